@@ -2,120 +2,338 @@ package main
 
 import (
 	"context"
-	"log"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	"github.com/mrussa/L0/internal/cache"
+	"github.com/mrussa/L0/internal/codec"
 	"github.com/mrussa/L0/internal/config"
 	"github.com/mrussa/L0/internal/db"
 	"github.com/mrussa/L0/internal/httpapi"
 	"github.com/mrussa/L0/internal/kafka"
+	"github.com/mrussa/L0/internal/logging"
+	"github.com/mrussa/L0/internal/metrics"
 	"github.com/mrussa/L0/internal/repo"
+	"github.com/mrussa/L0/internal/tracing"
+	"github.com/mrussa/L0/internal/validate"
+	"github.com/mrussa/L0/internal/warmup"
 )
 
 var version = "dev"
 
-func warmCache(ctx context.Context, r *repo.OrdersRepo, c *cache.OrdersCache, limit int, logf func(string, ...any)) {
-	if limit <= 0 {
-		return
+var errWarmPending = errors.New("cache warm-up in progress")
+
+func fatal(log *slog.Logger, msg string, args ...any) {
+	log.Error(msg, args...)
+	os.Exit(1)
+}
+
+func newWarmSource(cfg config.Config, r *repo.OrdersRepo, access *warmup.AccessLog) warmup.Source {
+	switch cfg.CacheWarmStrategy {
+	case "frequent":
+		return warmup.Frequent(access)
+	case "file":
+		return warmup.File(cfg.CacheWarmFile)
 	}
+	return warmup.Recent(r)
+}
 
-	ctxList, cancel := context.WithTimeout(ctx, 10*time.Second)
-	defer cancel()
+func loadAccessLog(log *slog.Logger, path string) *warmup.AccessLog {
+	if path == "" {
+		return nil
+	}
+	l := warmup.NewAccessLog(0)
+	if err := l.Load(path); err != nil {
+		log.Warn("access log not loaded", "err", err)
+	} else {
+		log.Info("access log loaded", "uids", l.Len(), "path", path)
+	}
+	return l
+}
+
+type snapshotter interface {
+	SaveSnapshot(path string) (int, error)
+	LoadSnapshot(path string, maxAge time.Duration) (int, error)
+}
+
+func restoreSnapshot(log *slog.Logger, c cache.Cache, path string, maxAge time.Duration) bool {
+	s, ok := c.(snapshotter)
+	if !ok || path == "" {
+		return false
+	}
+	n, err := s.LoadSnapshot(path, maxAge)
+	if err != nil {
+		log.Warn("snapshot not loaded", "err", err)
+		return false
+	}
+	log.Info("snapshot loaded", "entries", n, "path", path)
+	return true
+}
 
-	uids, err := r.ListRecentOrderUIDs(ctxList, limit)
+func saveSnapshot(log *slog.Logger, c cache.Cache, path string) {
+	s, ok := c.(snapshotter)
+	if !ok || path == "" {
+		return
+	}
+	n, err := s.SaveSnapshot(path)
 	if err != nil {
-		logf("[CACHE] warm list: %v", err)
+		log.Error("snapshot save failed", "err", err)
 		return
 	}
+	log.Info("snapshot saved", "entries", n, "path", path)
+}
 
-	ok, failed := 0, 0
-	for _, uid := range uids {
-		ctxOne, cancelOne := context.WithTimeout(ctx, 2*time.Second)
-		o, err := r.GetOrder(ctxOne, uid)
-		cancelOne()
-		if err != nil {
-			failed++
-			logf("[CACHE] warm %s: %v", uid, err)
-			continue
-		}
-		c.Set(uid, o)
-		ok++
+func newCache(log *slog.Logger, cfg config.Config) (cache.Cache, error) {
+	if cfg.CacheBackend == "redis" {
+		log.Info("cache backend", "backend", "redis", "addr", cfg.RedisAddr, "db", cfg.RedisDB)
+		return cache.NewRedis(cfg.RedisAddr,
+			cache.WithRedisPassword(cfg.RedisPassword),
+			cache.WithRedisDB(cfg.RedisDB),
+			cache.WithRedisTTL(cfg.CacheTTL),
+			cache.WithRedisLogger(log),
+		)
 	}
-	logf("[CACHE] warmed: %d ok, %d failed, size=%d", ok, failed, c.Len())
+	return cache.New(cache.WithMaxEntries(cfg.CacheMaxEntries), cache.WithTTL(cfg.CacheTTL)), nil
 }
 
 func main() {
 	cfg, err := config.Load()
 	if err != nil {
-		log.Fatalf("[CFG] %v", err)
+		fmt.Fprintf(os.Stderr, "config: %v\n", err)
+		os.Exit(1)
+	}
+	logs, err := logging.New(os.Stderr, logging.Config{Format: cfg.LogFormat, Level: cfg.LogLevel, Levels: cfg.LogLevels})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "logging: %v\n", err)
+		os.Exit(1)
 	}
-	log.Printf("[APP] version=%s", version)
-	log.Printf("[CFG] http=%s dsn_present=%t cache_warm=%d", cfg.HTTPAddr, cfg.PostgresDSN != "", cfg.CacheWarmLimit)
-	log.Printf("[KAFKA] brokers=%s topic=%s group=%s", cfg.KafkaBrokers, cfg.KafkaTopic, cfg.KafkaGroup)
+	appLog := logs.Component("app")
+	dbLog := logs.Component("db")
+	cacheLog := logs.Component("cache")
+	httpLog := logs.Component("http")
+	kafkaLog := logs.Component("kafka")
+	slog.SetDefault(appLog)
+
+	appLog.Info("starting", "version", version)
+	appLog.Info("config",
+		"http", cfg.HTTPAddr, "dsn_present", cfg.PostgresDSN != "",
+		"cache_warm", cfg.CacheWarmLimit, "warm_strategy", cfg.CacheWarmStrategy, "warm_async", cfg.CacheWarmAsync)
+	kafkaLog.Info("config", "brokers", cfg.KafkaBrokers, "topic", cfg.KafkaTopic, "group", cfg.KafkaGroup, "dlq", cfg.KafkaDLQTopic,
+		"format", cfg.KafkaFormat, "schema_registry", cfg.SchemaRegistryURL,
+		"json_strict", cfg.KafkaJSONStrict, "json_schema", cfg.KafkaJSONSchema)
 
 	rootCtx := context.Background()
 
+	shutdownTracing, err := tracing.Setup(rootCtx, tracing.Config{
+		Exporter:    cfg.TracingExporter,
+		Endpoint:    cfg.TracingEndpoint,
+		ServiceName: cfg.TracingServiceName,
+		Version:     version,
+	})
+	if err != nil {
+		fatal(appLog, "tracing setup failed", "err", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			appLog.Warn("tracing shutdown failed", "err", err)
+		}
+	}()
+
 	pool, err := db.NewPool(rootCtx, cfg.PostgresDSN)
 	if err != nil {
-		log.Fatalf("[DB] new pool: %v", err)
+		fatal(dbLog, "new pool failed", "err", err)
 	}
 	if err := db.Ping(rootCtx, pool); err != nil {
 		pool.Close()
-		log.Fatalf("[DB] ping: %v", err)
+		fatal(dbLog, "ping failed", "err", err)
 	}
 	defer pool.Close()
-	log.Println("[DB] connected")
+	dbLog.Info("connected")
 
 	rpo := repo.NewOrdersRepo(pool)
-	c := cache.New()
+	c, err := newCache(cacheLog, cfg)
+	if err != nil {
+		pool.Close()
+		fatal(cacheLog, "cache setup failed", "err", err)
+	}
+	if cl, ok := c.(io.Closer); ok {
+		defer cl.Close()
+	}
+
+	access := loadAccessLog(cacheLog, cfg.CacheAccessLog)
+	warmer := warmup.New(newWarmSource(cfg, rpo, access), rpo, c, cacheLog)
+	warmer.Limit = cfg.CacheWarmLimit
+	warmer.Workers = cfg.CacheWarmWorkers
+	warmer.Budget = cfg.CacheWarmBudget
+	warm := func(ctx context.Context) (int, int, error) {
+		res, err := warmer.Run(ctx)
+		return res.Warmed, res.Failed, err
+	}
+	var warmed, rewarming atomic.Bool
+	adminWarm := func(ctx context.Context) (int, int, error) {
+		rewarming.Store(true)
+		defer rewarming.Store(false)
+		return warm(ctx)
+	}
+	needWarm := !restoreSnapshot(cacheLog, c, cfg.CacheSnapshotPath, cfg.CacheSnapshotMaxAge)
+	if !needWarm {
+		warmed.Store(true)
+	} else if !cfg.CacheWarmAsync {
+		warm(rootCtx)
+		warmed.Store(true)
+	}
 
-	warmCache(rootCtx, rpo, c, cfg.CacheWarmLimit, log.Printf)
+	cons := kafka.NewConsumer(cfg.KafkaBrokers, cfg.KafkaTopic, cfg.KafkaGroup, rpo, c, kafkaLog)
+	cons.DLQTopic = cfg.KafkaDLQTopic
+	cons.RetryAttempts = cfg.KafkaRetryAttempts
+	cons.RetryBase = cfg.KafkaRetryBase
+	cons.RetryMax = cfg.KafkaRetryMax
+	cons.OnRetryExhausted = kafka.ExhaustPolicy(cfg.KafkaRetryExhausted)
+	cons.Workers = cfg.KafkaWorkers
+	cons.BatchSize = cfg.KafkaBatchSize
+	cons.BatchWait = cfg.KafkaBatchWait
+	avroDec, err := codec.NewAvro(cfg.SchemaRegistryURL, nil)
+	if err != nil {
+		pool.Close()
+		fatal(kafkaLog, "avro decoder setup failed", "err", err)
+	}
+	jsonDec := &codec.JSONDecoder{Strict: cfg.KafkaJSONStrict}
+	if cfg.KafkaJSONSchema != "" {
+		if jsonDec.Schema, err = codec.CompileSchema(cfg.KafkaJSONSchema); err != nil {
+			pool.Close()
+			fatal(kafkaLog, "json schema setup failed", "err", err)
+		}
+	}
+	cons.Decoders = map[codec.Format]kafka.Decoder{
+		codec.FormatJSON:     jsonDec.Decode,
+		codec.FormatProtobuf: codec.Protobuf,
+		codec.FormatAvro:     avroDec.Decode,
+	}
+	cons.Decode = cons.Decoders[cfg.KafkaFormat]
+	validator, err := validate.Parse(cfg.ValidationRules)
+	if err != nil {
+		pool.Close()
+		fatal(kafkaLog, "validation rules setup failed", "err", err)
+	}
+	cons.Validate = validator.Validate
+	kafkaLog.Info("validation rules", "rules", validator.Names())
+	sup := kafka.NewSupervisor(cons, kafkaLog)
+	sup.RestartBase = cfg.KafkaRestartBase
+	sup.RestartMax = cfg.KafkaRestartMax
+	sup.MaxFailures = cfg.KafkaMaxFailures
 
-	api := httpapi.New(rpo, c, log.Printf, version)
+	opts := []httpapi.Option{
+		httpapi.WithWarmer(adminWarm),
+		httpapi.WithNegativeTTL(cfg.CacheNegativeTTL),
+		httpapi.WithReadyCheck("postgres", func(ctx context.Context) (map[string]any, error) {
+			return nil, rpo.Ping(ctx)
+		}),
+		httpapi.WithReadyCheck("kafka", func(context.Context) (map[string]any, error) {
+			return sup.Ready()
+		}),
+		httpapi.WithReadyCheck("warmup", func(context.Context) (map[string]any, error) {
+			if !warmed.Load() {
+				return map[string]any{"strategy": cfg.CacheWarmStrategy}, errWarmPending
+			}
+			if rewarming.Load() {
+				return map[string]any{"strategy": cfg.CacheWarmStrategy, "running": true}, nil
+			}
+			return nil, nil
+		}),
+	}
+	if cfg.AdminToken != "" {
+		opts = append(opts, httpapi.WithAdminToken(cfg.AdminToken))
+	} else {
+		httpLog.Info("admin endpoints disabled, set ADMIN_TOKEN to enable")
+	}
+	if cfg.LogAccess {
+		opts = append(opts, httpapi.WithAccessLog(logs.Component("access")))
+	}
+	if access != nil {
+		opts = append(opts, httpapi.WithAccessRecorder(access))
+	}
+	var m *metrics.Metrics
+	if cfg.MetricsEnabled {
+		m = metrics.New()
+		m.Register(metrics.NewPoolCollector(pool.Stat))
+		m.Register(metrics.NewCacheCollector(c.Stats))
+		opts = append(opts, httpapi.WithMetrics(m))
+		cons.Metrics = m
+		sup.Metrics = m
+	}
+	api := httpapi.New(rpo, c, httpLog, version, opts...)
 	srv := &http.Server{
 		Addr:              cfg.HTTPAddr,
 		Handler:           api.Routes(),
+		ErrorLog:          slog.NewLogLogger(httpLog.Handler(), slog.LevelWarn),
 		ReadHeaderTimeout: 5 * time.Second,
 		WriteTimeout:      10 * time.Second,
 		IdleTimeout:       60 * time.Second,
 	}
 
-	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	sigCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
+	ctx, abort := context.WithCancelCause(sigCtx)
+	defer abort(nil)
 
 	var wg sync.WaitGroup
 
-	cons := kafka.NewConsumer(cfg.KafkaBrokers, cfg.KafkaTopic, cfg.KafkaGroup, rpo, c, log.Printf)
+	if needWarm && cfg.CacheWarmAsync {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cacheLog.Info("warming in background", "strategy", cfg.CacheWarmStrategy)
+			warm(ctx)
+			warmed.Store(true)
+		}()
+	}
+
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		_ = cons.Run(ctx)
+		if err := sup.Run(ctx); errors.Is(err, kafka.ErrTooManyFailures) {
+			abort(err)
+		}
 	}()
 
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		log.Printf("[HTTP] listening on %s", cfg.HTTPAddr)
+		httpLog.Info("listening", "addr", cfg.HTTPAddr)
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("[HTTP] %v", err)
+			fatal(httpLog, "listen failed", "err", err)
 		}
 	}()
 
 	<-ctx.Done()
 
-	log.Printf("[HTTP] shutting down...")
+	httpLog.Info("shutting down")
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	if err := srv.Shutdown(shutdownCtx); err != nil {
-		log.Printf("[HTTP] shutdown error: %v", err)
+		httpLog.Warn("shutdown failed", "err", err)
 	}
 
 	wg.Wait()
-	log.Printf("[HTTP] bye")
+	saveSnapshot(cacheLog, c, cfg.CacheSnapshotPath)
+	if access != nil {
+		if err := access.Save(cfg.CacheAccessLog); err != nil {
+			cacheLog.Error("access log save failed", "err", err)
+		}
+	}
+	if err := context.Cause(ctx); errors.Is(err, kafka.ErrTooManyFailures) {
+		fatal(appLog, "exiting: kafka consumer failed", "err", err)
+	}
+	appLog.Info("bye")
 }
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+
+	"github.com/mrussa/L0/internal/ordergen"
+)
+
+type options struct {
+	mode        string
+	rps         float64
+	n           int
+	duration    time.Duration
+	concurrency int
+	seed        uint64
+	report      time.Duration
+
+	brokers string
+	topic   string
+
+	baseURL   string
+	uids      int
+	missRatio float64
+	timeout   time.Duration
+}
+
+func main() {
+	var opt options
+	flag.StringVar(&opt.mode, "mode", "kafka", "kafka: publish generated orders; http: GET /order/{uid}")
+	flag.Float64Var(&opt.rps, "rps", 100, "target requests (messages) per second, 0 = as fast as possible")
+	flag.IntVar(&opt.n, "n", 1000, "total requests, 0 = until -duration elapses")
+	flag.DurationVar(&opt.duration, "duration", 0, "stop after this long, 0 = until -n is reached")
+	flag.IntVar(&opt.concurrency, "c", 16, "concurrent workers")
+	flag.Uint64Var(&opt.seed, "seed", 1, "generator seed; the same seed yields the same order UIDs")
+	flag.DurationVar(&opt.report, "report", 5*time.Second, "progress report interval, 0 = only final report")
+	flag.StringVar(&opt.brokers, "brokers", envOr("KAFKA_BROKERS", "localhost:9092"), "kafka mode: comma-separated brokers")
+	flag.StringVar(&opt.topic, "topic", envOr("KAFKA_TOPIC", "orders"), "kafka mode: topic")
+	flag.StringVar(&opt.baseURL, "url", "http://localhost:8081", "http mode: API base URL")
+	flag.IntVar(&opt.uids, "uids", 1000, "http mode: request UIDs 0..uids-1 of -seed (as published by kafka mode)")
+	flag.Float64Var(&opt.missRatio, "miss", 0, "http mode: share of requests for nonexistent UIDs (0..1)")
+	flag.DurationVar(&opt.timeout, "timeout", 5*time.Second, "per-request timeout")
+	flag.Parse()
+
+	if opt.n <= 0 && opt.duration <= 0 {
+		log.Fatalf("[LOADGEN] set -n or -duration")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if opt.duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opt.duration)
+		defer cancel()
+	}
+
+	var (
+		job func(context.Context, int) (string, bool)
+		err error
+	)
+	switch opt.mode {
+	case "kafka":
+		var closeFn func() error
+		job, closeFn = kafkaJob(opt)
+		defer closeFn()
+	case "http":
+		job, err = httpJob(opt)
+	default:
+		err = fmt.Errorf("unknown -mode %q", opt.mode)
+	}
+	if err != nil {
+		log.Fatalf("[LOADGEN] %v", err)
+	}
+
+	log.Printf("[LOADGEN] mode=%s rps=%.0f n=%d duration=%s c=%d seed=%d", opt.mode, opt.rps, opt.n, opt.duration, opt.concurrency, opt.seed)
+	st := run(ctx, opt, job)
+	st.summary().print(os.Stdout)
+}
+
+func run(ctx context.Context, opt options, job func(context.Context, int) (string, bool)) *stats {
+	st := newStats()
+	jobs := make(chan int, opt.concurrency)
+
+	var wg sync.WaitGroup
+	for w := 0; w < max(opt.concurrency, 1); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				start := time.Now()
+				outcome, failed := job(ctx, i)
+				st.record(time.Since(start), outcome, failed)
+			}
+		}()
+	}
+
+	if opt.report > 0 {
+		t := time.NewTicker(opt.report)
+		defer t.Stop()
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-t.C:
+					s := st.summary()
+					log.Printf("[LOADGEN] %d done, %.1f rps, p99=%s, errors=%d", s.Total, s.RPS, s.P99, s.Errors)
+				}
+			}
+		}()
+	}
+
+	var tick <-chan time.Time
+	if opt.rps > 0 {
+		t := time.NewTicker(time.Duration(float64(time.Second) / opt.rps))
+		defer t.Stop()
+		tick = t.C
+	}
+
+feed:
+	for i := 0; opt.n <= 0 || i < opt.n; i++ {
+		if tick != nil {
+			select {
+			case <-ctx.Done():
+				break feed
+			case <-tick:
+			}
+		}
+		select {
+		case <-ctx.Done():
+			break feed
+		case jobs <- i:
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	return st
+}
+
+func kafkaJob(opt options) (func(context.Context, int) (string, bool), func() error) {
+	gen := ordergen.New(opt.seed)
+	w := &kafka.Writer{
+		Addr:         kafka.TCP(splitCSV(opt.brokers)...),
+		Topic:        opt.topic,
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireAll,
+		BatchTimeout: 5 * time.Millisecond,
+		WriteTimeout: opt.timeout,
+	}
+	job := func(ctx context.Context, i int) (string, bool) {
+		o := gen.Order(i)
+		b, err := json.Marshal(o)
+		if err != nil {
+			return "marshal_error", true
+		}
+		if err := w.WriteMessages(ctx, kafka.Message{Key: []byte(o.OrderUID), Value: b}); err != nil {
+			return "error", true
+		}
+		return "ok", false
+	}
+	return job, w.Close
+}
+
+func httpJob(opt options) (func(context.Context, int) (string, bool), error) {
+	base, err := url.Parse(strings.TrimRight(opt.baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("bad -url: %w", err)
+	}
+	if opt.uids <= 0 {
+		return nil, fmt.Errorf("-uids must be positive")
+	}
+
+	gen := ordergen.New(opt.seed)
+	client := &http.Client{
+		Timeout: opt.timeout,
+		Transport: &http.Transport{
+			MaxIdleConns:        opt.concurrency,
+			MaxIdleConnsPerHost: opt.concurrency,
+		},
+	}
+	job := func(ctx context.Context, i int) (string, bool) {
+		uid := gen.UID(i % opt.uids)
+		if opt.missRatio > 0 && rand.Float64() < opt.missRatio {
+			uid = "missing-" + strconv.Itoa(i)
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, base.String()+"/order/"+url.PathEscape(uid), nil)
+		if err != nil {
+			return "error", true
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return "error", true
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		return strconv.Itoa(resp.StatusCode), resp.StatusCode >= 500
+	}
+	return job, nil
+}
+
+func envOr(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+func splitCSV(s string) []string {
+	var out []string
+	for _, p := range strings.Split(s, ",") {
+		if t := strings.TrimSpace(p); t != "" {
+			out = append(out, t)
+		}
+	}
+	return out
+}
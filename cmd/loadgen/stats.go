@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+type stats struct {
+	mu        sync.Mutex
+	start     time.Time
+	latencies []time.Duration
+	outcomes  map[string]int
+	errors    int
+}
+
+func newStats() *stats {
+	return &stats{start: time.Now(), outcomes: make(map[string]int)}
+}
+
+func (s *stats) record(d time.Duration, outcome string, failed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latencies = append(s.latencies, d)
+	s.outcomes[outcome]++
+	if failed {
+		s.errors++
+	}
+}
+
+type summary struct {
+	Total     int
+	Errors    int
+	Elapsed   time.Duration
+	RPS       float64
+	P50       time.Duration
+	P90       time.Duration
+	P99       time.Duration
+	Max       time.Duration
+	Outcomes  map[string]int
+	ErrorRate float64
+}
+
+func (s *stats) summary() summary {
+	s.mu.Lock()
+	lat := append([]time.Duration(nil), s.latencies...)
+	outcomes := make(map[string]int, len(s.outcomes))
+	for k, v := range s.outcomes {
+		outcomes[k] = v
+	}
+	errs := s.errors
+	s.mu.Unlock()
+
+	sort.Slice(lat, func(i, j int) bool { return lat[i] < lat[j] })
+	elapsed := time.Since(s.start)
+	sum := summary{
+		Total:    len(lat),
+		Errors:   errs,
+		Elapsed:  elapsed,
+		Outcomes: outcomes,
+		P50:      percentile(lat, 50),
+		P90:      percentile(lat, 90),
+		P99:      percentile(lat, 99),
+	}
+	if len(lat) > 0 {
+		sum.Max = lat[len(lat)-1]
+		sum.ErrorRate = float64(errs) / float64(len(lat))
+	}
+	if elapsed > 0 {
+		sum.RPS = float64(len(lat)) / elapsed.Seconds()
+	}
+	return sum
+}
+
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	idx := int(float64(len(sorted))*p/100+0.5) - 1
+	idx = min(max(idx, 0), len(sorted)-1)
+	return sorted[idx]
+}
+
+func (s summary) print(w io.Writer) {
+	fmt.Fprintf(w, "requests: %d in %s (%.1f rps)\n", s.Total, s.Elapsed.Round(time.Millisecond), s.RPS)
+	fmt.Fprintf(w, "latency:  p50=%s p90=%s p99=%s max=%s\n", s.P50, s.P90, s.P99, s.Max)
+	fmt.Fprintf(w, "errors:   %d (%.2f%%)\n", s.Errors, 100*s.ErrorRate)
+
+	keys := make([]string, 0, len(s.Outcomes))
+	for k := range s.Outcomes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(w, "  %-12s %d\n", k, s.Outcomes[k])
+	}
+}
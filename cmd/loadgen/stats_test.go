@@ -0,0 +1,43 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPercentile(t *testing.T) {
+	require.Zero(t, percentile(nil, 50))
+
+	var lat []time.Duration
+	for i := 1; i <= 100; i++ {
+		lat = append(lat, time.Duration(i)*time.Millisecond)
+	}
+	require.Equal(t, 50*time.Millisecond, percentile(lat, 50))
+	require.Equal(t, 99*time.Millisecond, percentile(lat, 99))
+	require.Equal(t, 100*time.Millisecond, percentile(lat, 100))
+	require.Equal(t, time.Millisecond, percentile(lat, 0))
+}
+
+func TestStats_Summary(t *testing.T) {
+	s := newStats()
+	s.record(10*time.Millisecond, "200", false)
+	s.record(30*time.Millisecond, "200", false)
+	s.record(20*time.Millisecond, "500", true)
+	s.record(40*time.Millisecond, "error", true)
+
+	sum := s.summary()
+	require.Equal(t, 4, sum.Total)
+	require.Equal(t, 2, sum.Errors)
+	require.InDelta(t, 0.5, sum.ErrorRate, 1e-9)
+	require.Equal(t, 20*time.Millisecond, sum.P50)
+	require.Equal(t, 40*time.Millisecond, sum.Max)
+	require.Equal(t, map[string]int{"200": 2, "500": 1, "error": 1}, sum.Outcomes)
+
+	var buf bytes.Buffer
+	sum.print(&buf)
+	require.Contains(t, buf.String(), "p99=")
+	require.Contains(t, buf.String(), "errors:   2 (50.00%)")
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+type payload struct {
+	key   string
+	value []byte
+}
+
+func readInputs(paths []string, stdin io.Reader) ([]json.RawMessage, error) {
+	if len(paths) == 0 {
+		paths = []string{"-"}
+	}
+
+	var out []json.RawMessage
+	for _, p := range paths {
+		var (
+			docs []json.RawMessage
+			err  error
+		)
+		if p == "-" {
+			docs, err = decodeAll(stdin)
+			if err != nil {
+				return nil, fmt.Errorf("stdin: %w", err)
+			}
+		} else {
+			docs, err = readPath(p)
+			if err != nil {
+				return nil, err
+			}
+		}
+		out = append(out, docs...)
+	}
+	if len(out) == 0 {
+		return nil, errors.New("no orders in input")
+	}
+	return out, nil
+}
+
+func readPath(path string) ([]json.RawMessage, error) {
+	st, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !st.IsDir() {
+		return readFile(path)
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, e := range entries {
+		ext := strings.ToLower(filepath.Ext(e.Name()))
+		if !e.IsDir() && (ext == ".json" || ext == ".ndjson" || ext == ".jsonl") {
+			files = append(files, filepath.Join(path, e.Name()))
+		}
+	}
+	sort.Strings(files)
+
+	var out []json.RawMessage
+	for _, f := range files {
+		docs, err := readFile(f)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, docs...)
+	}
+	return out, nil
+}
+
+func readFile(path string) ([]json.RawMessage, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	docs, err := decodeAll(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return docs, nil
+}
+
+// decodeAll accepts both a single (possibly pretty-printed) JSON document
+// and NDJSON, since json.Decoder reads consecutive values either way.
+func decodeAll(r io.Reader) ([]json.RawMessage, error) {
+	dec := json.NewDecoder(r)
+	var out []json.RawMessage
+	for {
+		var doc json.RawMessage
+		err := dec.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			return out, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, doc); err != nil {
+			return nil, err
+		}
+		out = append(out, buf.Bytes())
+	}
+}
+
+func buildPayload(doc json.RawMessage, randomUID bool, key string) (payload, error) {
+	if !randomUID {
+		if key == "" {
+			var hdr struct {
+				OrderUID string `json:"order_uid"`
+			}
+			if err := json.Unmarshal(doc, &hdr); err != nil {
+				return payload{}, err
+			}
+			key = hdr.OrderUID
+		}
+		return payload{key: key, value: doc}, nil
+	}
+
+	var m map[string]any
+	dec := json.NewDecoder(bytes.NewReader(doc))
+	dec.UseNumber()
+	if err := dec.Decode(&m); err != nil {
+		return payload{}, err
+	}
+
+	uid := newUID()
+	m["order_uid"] = uid
+	if p, ok := m["payment"].(map[string]any); ok {
+		p["transaction"] = uid
+	}
+
+	b, err := json.Marshal(m)
+	if err != nil {
+		return payload{}, err
+	}
+	if key == "" {
+		key = uid
+	}
+	return payload{key: key, value: b}, nil
+}
+
+func newUID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b) + "test"
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeAll_PrettyAndNDJSON(t *testing.T) {
+	docs, err := decodeAll(strings.NewReader("{\n  \"order_uid\": \"a\"\n}\n"))
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	require.JSONEq(t, `{"order_uid":"a"}`, string(docs[0]))
+	require.NotContains(t, string(docs[0]), "\n")
+
+	docs, err = decodeAll(strings.NewReader("{\"order_uid\":\"a\"}\n\n{\"order_uid\":\"b\"}\n"))
+	require.NoError(t, err)
+	require.Len(t, docs, 2)
+
+	_, err = decodeAll(strings.NewReader(`{"order_uid":`))
+	require.Error(t, err)
+}
+
+func TestReadInputs_DirectoryAndStdin(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b.json"), []byte(`{"order_uid":"b"}`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.ndjson"), []byte("{\"order_uid\":\"a1\"}\n{\"order_uid\":\"a2\"}\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("ignored"), 0o600))
+
+	docs, err := readInputs([]string{dir}, nil)
+	require.NoError(t, err)
+	require.Len(t, docs, 3)
+	require.JSONEq(t, `{"order_uid":"a1"}`, string(docs[0]))
+	require.JSONEq(t, `{"order_uid":"b"}`, string(docs[2]))
+
+	docs, err = readInputs(nil, strings.NewReader(`{"order_uid":"s"}`))
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+
+	_, err = readInputs(nil, strings.NewReader(""))
+	require.ErrorContains(t, err, "no orders")
+
+	_, err = readInputs([]string{filepath.Join(dir, "missing.json")}, nil)
+	require.Error(t, err)
+}
+
+func TestBuildPayload_KeyFromOrderUID(t *testing.T) {
+	doc := json.RawMessage(`{"order_uid":"u1","payment":{"transaction":"u1","amount":1817}}`)
+
+	p, err := buildPayload(doc, false, "")
+	require.NoError(t, err)
+	require.Equal(t, "u1", p.key)
+	require.Equal(t, []byte(doc), p.value)
+
+	p, err = buildPayload(doc, false, "explicit")
+	require.NoError(t, err)
+	require.Equal(t, "explicit", p.key)
+}
+
+func TestBuildPayload_RandomUID(t *testing.T) {
+	doc := json.RawMessage(`{"order_uid":"u1","payment":{"transaction":"u1","amount":1817},"extra":true}`)
+
+	p1, err := buildPayload(doc, true, "")
+	require.NoError(t, err)
+	p2, err := buildPayload(doc, true, "")
+	require.NoError(t, err)
+	require.NotEqual(t, p1.key, p2.key)
+
+	var got struct {
+		OrderUID string `json:"order_uid"`
+		Payment  struct {
+			Transaction string `json:"transaction"`
+			Amount      int    `json:"amount"`
+		} `json:"payment"`
+		Extra bool `json:"extra"`
+	}
+	require.NoError(t, json.Unmarshal(p1.value, &got))
+	require.Equal(t, p1.key, got.OrderUID)
+	require.Equal(t, p1.key, got.Payment.Transaction)
+	require.Equal(t, 1817, got.Payment.Amount)
+	require.True(t, got.Extra, "остальные поля шаблона сохраняются")
+
+	_, err = buildPayload(json.RawMessage(`[1]`), true, "")
+	require.Error(t, err)
+}
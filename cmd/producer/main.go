@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+const writeChunk = 100
+
+type options struct {
+	brokers   string
+	topic     string
+	key       string
+	rate      float64
+	count     int
+	randomUID bool
+	dryRun    bool
+}
+
+func main() {
+	var opt options
+	flag.StringVar(&opt.brokers, "brokers", envOr("KAFKA_BROKERS", "localhost:9092"), "comma-separated Kafka brokers")
+	flag.StringVar(&opt.topic, "topic", envOr("KAFKA_TOPIC", "orders"), "target topic")
+	flag.StringVar(&opt.key, "key", "", "message key (default: order_uid of each order)")
+	flag.Float64Var(&opt.rate, "rate", 0, "messages per second, 0 = unlimited")
+	flag.IntVar(&opt.count, "count", 0, "total messages to send, cycling over inputs; 0 = each input once")
+	flag.BoolVar(&opt.randomUID, "random-uid", false, "use inputs as templates and generate a fresh order_uid per message")
+	flag.BoolVar(&opt.dryRun, "dry-run", false, "print messages to stdout instead of sending")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [file|dir|-]...\n\nReads JSON/NDJSON orders from files, directories or stdin and publishes them to Kafka.\n\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	docs, err := readInputs(flag.Args(), os.Stdin)
+	if err != nil {
+		log.Fatalf("[PRODUCER] read input: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	sent, err := run(ctx, opt, docs)
+	log.Printf("[PRODUCER] sent %d message(s) to %s", sent, opt.topic)
+	if err != nil && ctx.Err() == nil {
+		log.Fatalf("[PRODUCER] %v", err)
+	}
+}
+
+func run(ctx context.Context, opt options, docs []json.RawMessage) (int, error) {
+	total := opt.count
+	if total <= 0 {
+		total = len(docs)
+	}
+
+	var send func(context.Context, []kafka.Message) error
+	if opt.dryRun {
+		send = func(_ context.Context, msgs []kafka.Message) error {
+			for _, m := range msgs {
+				fmt.Printf("%s\t%s\n", m.Key, m.Value)
+			}
+			return nil
+		}
+	} else {
+		w := &kafka.Writer{
+			Addr:         kafka.TCP(splitCSV(opt.brokers)...),
+			Topic:        opt.topic,
+			Balancer:     &kafka.Hash{},
+			RequiredAcks: kafka.RequireAll,
+			BatchTimeout: 10 * time.Millisecond,
+		}
+		defer w.Close()
+		send = func(ctx context.Context, msgs []kafka.Message) error { return w.WriteMessages(ctx, msgs...) }
+	}
+
+	var tick <-chan time.Time
+	chunk := writeChunk
+	if opt.rate > 0 {
+		t := time.NewTicker(time.Duration(float64(time.Second) / opt.rate))
+		defer t.Stop()
+		tick = t.C
+		chunk = 1
+	}
+
+	sent := 0
+	buf := make([]kafka.Message, 0, chunk)
+	for i := 0; i < total; i++ {
+		p, err := buildPayload(docs[i%len(docs)], opt.randomUID, opt.key)
+		if err != nil {
+			return sent, fmt.Errorf("message %d: %w", i, err)
+		}
+		buf = append(buf, kafka.Message{Key: []byte(p.key), Value: p.value})
+		if len(buf) < chunk && i < total-1 {
+			continue
+		}
+
+		if tick != nil {
+			select {
+			case <-ctx.Done():
+				return sent, ctx.Err()
+			case <-tick:
+			}
+		}
+		if err := send(ctx, buf); err != nil {
+			return sent, err
+		}
+		sent += len(buf)
+		buf = buf[:0]
+	}
+	return sent, nil
+}
+
+func envOr(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+func splitCSV(s string) []string {
+	var out []string
+	for _, p := range strings.Split(s, ",") {
+		if t := strings.TrimSpace(p); t != "" {
+			out = append(out, t)
+		}
+	}
+	return out
+}
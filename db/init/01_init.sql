@@ -13,9 +13,13 @@ CREATE TABLE IF NOT EXISTS orders (
   shardkey           text NOT NULL,
   sm_id              integer NOT NULL,
   date_created       timestamptz NOT NULL,
-  oof_shard          text NOT NULL
+  oof_shard          text NOT NULL,
+  search             tsvector NOT NULL DEFAULT ''::tsvector
 );
 
+-- Для баз, созданных до появления поиска
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS search tsvector NOT NULL DEFAULT ''::tsvector;
+
 CREATE TABLE IF NOT EXISTS order_payment (
   order_uid      varchar(100) PRIMARY KEY REFERENCES orders(order_uid) ON DELETE CASCADE,
   transaction_id text NOT NULL,
@@ -59,4 +63,25 @@ CREATE TABLE IF NOT EXISTS order_delivery (
 
 -- Индексы
 CREATE INDEX IF NOT EXISTS idx_order_items_order_uid ON order_items(order_uid);
-CREATE INDEX IF NOT EXISTS idx_orders_date_created   ON orders(date_created DESC);
+CREATE INDEX IF NOT EXISTS idx_orders_date_created   ON orders(date_created DESC, order_uid DESC);
+CREATE INDEX IF NOT EXISTS idx_orders_customer_created ON orders(customer_id, date_created DESC, order_uid DESC);
+CREATE INDEX IF NOT EXISTS idx_orders_track_number   ON orders(track_number);
+CREATE INDEX IF NOT EXISTS idx_orders_delivery_created ON orders(delivery_service, date_created DESC, order_uid DESC);
+CREATE INDEX IF NOT EXISTS idx_order_payment_currency ON order_payment(currency);
+CREATE INDEX IF NOT EXISTS idx_order_payment_provider ON order_payment(provider);
+CREATE INDEX IF NOT EXISTS idx_order_payment_transaction ON order_payment(transaction_id);
+CREATE INDEX IF NOT EXISTS idx_order_items_rid       ON order_items(rid);
+CREATE INDEX IF NOT EXISTS idx_order_items_chrt_id   ON order_items(chrt_id);
+CREATE INDEX IF NOT EXISTS idx_orders_search         ON orders USING gin(search);
+
+-- Бэкфилл поискового вектора для заказов, записанных до появления поиска
+-- (те же поля и веса, что и в repo.searchText)
+UPDATE orders o SET search =
+  setweight(to_tsvector('simple', concat_ws(' ', d.name, d.email, d.phone)), 'A') ||
+  setweight(to_tsvector('simple', concat_ws(' ', d.city, d.address)), 'B') ||
+  setweight(to_tsvector('simple', coalesce((
+    SELECT string_agg(i.name || ' ' || i.brand, ' ' ORDER BY i.id)
+    FROM order_items i WHERE i.order_uid = o.order_uid
+  ), '')), 'C')
+FROM order_delivery d
+WHERE d.order_uid = o.order_uid AND o.search = ''::tsvector;
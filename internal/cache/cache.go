@@ -1,43 +1,234 @@
 package cache
 
 import (
+	"container/list"
 	"sync"
+	"time"
 
 	"github.com/mrussa/L0/internal/repo"
 )
 
-const defaultCap = 256
+const (
+	defaultCap        = 256
+	defaultMaxEntries = 10000
+	defaultShards     = 16
+)
+
+// SizeUnknown is reported by backends that cannot count entries cheaply.
+const SizeUnknown = -1
+
+type Cache interface {
+	Get(uid string) (repo.Order, bool)
+	Set(uid string, o repo.Order)
+	Delete(uid string)
+	Len() int
+	Purge() int
+	Stats() Stats
+}
+
+var (
+	_ Cache = (*OrdersCache)(nil)
+	_ Cache = (*RedisCache)(nil)
+)
+
+type entry struct {
+	uid     string
+	order   repo.Order
+	expires time.Time
+}
+
+type shard struct {
+	mu  sync.Mutex
+	m   map[string]*list.Element
+	lru *list.List
+
+	maxEntries int
+
+	hits, misses, evictions, expired uint64
+}
 
 type OrdersCache struct {
-	mu sync.RWMutex
-	m  map[string]repo.Order
+	shards []*shard
+
+	maxEntries int
+	nShards    int
+	ttl        time.Duration
+	now        func() time.Time
 }
 
-func New() *OrdersCache {
-	return &OrdersCache{m: make(map[string]repo.Order, defaultCap)}
+type Stats struct {
+	Backend    string  `json:"backend"`
+	Size       int     `json:"size"`
+	MaxEntries int     `json:"max_entries"`
+	Shards     int     `json:"shards"`
+	Hits       uint64  `json:"hits"`
+	Misses     uint64  `json:"misses"`
+	Evictions  uint64  `json:"evictions"`
+	Expired    uint64  `json:"expired"`
+	Errors     uint64  `json:"errors"`
+	HitRatio   float64 `json:"hit_ratio"`
+}
+
+type Option func(*OrdersCache)
+
+func WithMaxEntries(n int) Option {
+	return func(c *OrdersCache) { c.maxEntries = n }
+}
+
+func WithTTL(d time.Duration) Option {
+	return func(c *OrdersCache) { c.ttl = d }
+}
+
+func WithClock(now func() time.Time) Option {
+	return func(c *OrdersCache) { c.now = now }
+}
+
+func WithShards(n int) Option {
+	return func(c *OrdersCache) { c.nShards = n }
+}
+
+func New(opts ...Option) *OrdersCache {
+	c := &OrdersCache{
+		maxEntries: defaultMaxEntries,
+		nShards:    defaultShards,
+		now:        time.Now,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.nShards < 1 {
+		c.nShards = 1
+	}
+	if c.maxEntries > 0 {
+		c.nShards = min(c.nShards, c.maxEntries)
+	}
+
+	perShard, size := 0, defaultCap/c.nShards+1
+	if c.maxEntries > 0 {
+		perShard = (c.maxEntries + c.nShards - 1) / c.nShards
+		size = min(size, perShard)
+	}
+	c.shards = make([]*shard, c.nShards)
+	for i := range c.shards {
+		c.shards[i] = &shard{
+			m:          make(map[string]*list.Element, size),
+			lru:        list.New(),
+			maxEntries: perShard,
+		}
+	}
+	return c
+}
+
+func (c *OrdersCache) shardFor(uid string) *shard {
+	if len(c.shards) == 1 {
+		return c.shards[0]
+	}
+	h := uint32(2166136261)
+	for i := 0; i < len(uid); i++ {
+		h ^= uint32(uid[i])
+		h *= 16777619
+	}
+	return c.shards[h%uint32(len(c.shards))]
 }
 
 func (c *OrdersCache) Get(uid string) (repo.Order, bool) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-	o, ok := c.m[uid]
-	return o, ok
+	s := c.shardFor(uid)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	el, ok := s.m[uid]
+	if !ok {
+		s.misses++
+		return repo.Order{}, false
+	}
+	e := el.Value.(*entry)
+	if c.isExpired(e) {
+		s.remove(el)
+		s.expired++
+		s.misses++
+		return repo.Order{}, false
+	}
+	s.lru.MoveToFront(el)
+	s.hits++
+	return e.order, true
 }
 
 func (c *OrdersCache) Set(uid string, o repo.Order) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	c.m[uid] = o
+	var expires time.Time
+	if c.ttl > 0 {
+		expires = c.now().Add(c.ttl)
+	}
+	c.shardFor(uid).put(uid, o, expires)
 }
 
 func (c *OrdersCache) Len() int {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-	return len(c.m)
+	n := 0
+	for _, s := range c.shards {
+		s.mu.Lock()
+		n += s.lru.Len()
+		s.mu.Unlock()
+	}
+	return n
 }
 
 func (c *OrdersCache) Delete(uid string) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	delete(c.m, uid)
+	s := c.shardFor(uid)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if el, ok := s.m[uid]; ok {
+		s.remove(el)
+	}
+}
+
+func (c *OrdersCache) Purge() int {
+	n := 0
+	for _, s := range c.shards {
+		s.mu.Lock()
+		n += s.lru.Len()
+		s.lru.Init()
+		clear(s.m)
+		s.mu.Unlock()
+	}
+	return n
+}
+
+func (c *OrdersCache) Stats() Stats {
+	st := Stats{Backend: "memory", MaxEntries: c.maxEntries, Shards: len(c.shards)}
+	for _, s := range c.shards {
+		s.mu.Lock()
+		st.Size += s.lru.Len()
+		st.Hits += s.hits
+		st.Misses += s.misses
+		st.Evictions += s.evictions
+		st.Expired += s.expired
+		s.mu.Unlock()
+	}
+	if total := st.Hits + st.Misses; total > 0 {
+		st.HitRatio = float64(st.Hits) / float64(total)
+	}
+	return st
+}
+
+func (c *OrdersCache) isExpired(e *entry) bool {
+	return !e.expires.IsZero() && !c.now().Before(e.expires)
+}
+
+func (s *shard) put(uid string, o repo.Order, expires time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if el, ok := s.m[uid]; ok {
+		e := el.Value.(*entry)
+		e.order, e.expires = o, expires
+		s.lru.MoveToFront(el)
+		return
+	}
+	s.m[uid] = s.lru.PushFront(&entry{uid: uid, order: o, expires: expires})
+	if s.maxEntries > 0 && s.lru.Len() > s.maxEntries {
+		s.remove(s.lru.Back())
+		s.evictions++
+	}
+}
+
+func (s *shard) remove(el *list.Element) {
+	s.lru.Remove(el)
+	delete(s.m, el.Value.(*entry).uid)
 }
@@ -2,7 +2,10 @@ package cache_test
 
 import (
 	"fmt"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -110,3 +113,198 @@ func TestConcurrent_SetGetDelete(t *testing.T) {
 	<-doneDel
 	require.Equal(t, 0, c.Len(), "после удаления всех ключей кеш должен быть пуст")
 }
+
+func TestLRU_EvictsLeastRecentlyUsed(t *testing.T) {
+	t.Parallel()
+
+	c := cache.New(cache.WithMaxEntries(2), cache.WithShards(1))
+	c.Set("a", repo.Order{OrderUID: "a"})
+	c.Set("b", repo.Order{OrderUID: "b"})
+
+	_, ok := c.Get("a")
+	require.True(t, ok)
+
+	c.Set("c", repo.Order{OrderUID: "c"})
+	require.Equal(t, 2, c.Len())
+
+	_, ok = c.Get("b")
+	require.False(t, ok, "b давно не использовался и должен быть вытеснен")
+	_, ok = c.Get("a")
+	require.True(t, ok)
+	_, ok = c.Get("c")
+	require.True(t, ok)
+}
+
+func TestLRU_OverwriteRefreshesRecency(t *testing.T) {
+	t.Parallel()
+
+	c := cache.New(cache.WithMaxEntries(2), cache.WithShards(1))
+	c.Set("a", repo.Order{OrderUID: "a"})
+	c.Set("b", repo.Order{OrderUID: "b"})
+	c.Set("a", repo.Order{OrderUID: "a2"})
+	c.Set("c", repo.Order{OrderUID: "c"})
+
+	got, ok := c.Get("a")
+	require.True(t, ok)
+	require.Equal(t, "a2", got.OrderUID)
+	_, ok = c.Get("b")
+	require.False(t, ok)
+}
+
+func TestLRU_ZeroMaxEntriesIsUnbounded(t *testing.T) {
+	t.Parallel()
+
+	c := cache.New(cache.WithMaxEntries(0))
+	for i := 0; i < 20000; i++ {
+		k := fmt.Sprintf("k%d", i)
+		c.Set(k, repo.Order{OrderUID: k})
+	}
+	require.Equal(t, 20000, c.Len())
+}
+
+func TestTTL_ExpiresEntries(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := cache.New(cache.WithTTL(time.Minute), cache.WithClock(func() time.Time { return now }))
+	c.Set("a", repo.Order{OrderUID: "a"})
+
+	now = now.Add(59 * time.Second)
+	_, ok := c.Get("a")
+	require.True(t, ok)
+
+	c.Set("b", repo.Order{OrderUID: "b"})
+	now = now.Add(time.Second)
+	_, ok = c.Get("a")
+	require.False(t, ok, "запись с истёкшим TTL не должна отдаваться")
+	require.Equal(t, 1, c.Len(), "просроченная запись удаляется при обращении")
+
+	_, ok = c.Get("b")
+	require.True(t, ok)
+
+	c.Set("b", repo.Order{OrderUID: "b"})
+	now = now.Add(59 * time.Second)
+	_, ok = c.Get("b")
+	require.True(t, ok, "перезапись продлевает TTL")
+}
+
+func TestStats_CountsHitsMissesEvictionsExpired(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := cache.New(cache.WithMaxEntries(2), cache.WithShards(1), cache.WithTTL(time.Minute), cache.WithClock(func() time.Time { return now }))
+
+	require.Equal(t, cache.Stats{Backend: "memory", MaxEntries: 2, Shards: 1}, c.Stats())
+
+	c.Set("a", repo.Order{OrderUID: "a"})
+	c.Get("a")
+	c.Get("missing")
+	c.Set("b", repo.Order{OrderUID: "b"})
+	c.Set("c", repo.Order{OrderUID: "c"})
+
+	now = now.Add(time.Minute)
+	c.Get("b")
+
+	st := c.Stats()
+	require.Equal(t, 1, st.Size)
+	require.Equal(t, uint64(1), st.Hits)
+	require.Equal(t, uint64(2), st.Misses)
+	require.Equal(t, uint64(1), st.Evictions)
+	require.Equal(t, uint64(1), st.Expired)
+	require.InDelta(t, 1.0/3, st.HitRatio, 1e-9)
+}
+
+func TestPurge_RemovesAllKeepsCounters(t *testing.T) {
+	t.Parallel()
+
+	c := cache.New()
+	c.Set("a", repo.Order{OrderUID: "a"})
+	c.Set("b", repo.Order{OrderUID: "b"})
+	c.Get("a")
+
+	require.Equal(t, 2, c.Purge())
+	require.Equal(t, 0, c.Len())
+	_, ok := c.Get("a")
+	require.False(t, ok)
+
+	c.Set("a", repo.Order{OrderUID: "a"})
+	require.Equal(t, 1, c.Len())
+	require.Equal(t, uint64(1), c.Stats().Hits)
+}
+
+func TestShards_BoundAndSpread(t *testing.T) {
+	t.Parallel()
+
+	c := cache.New(cache.WithMaxEntries(1000), cache.WithShards(8))
+	for i := 0; i < 5000; i++ {
+		k := fmt.Sprintf("uid-%d", i)
+		c.Set(k, repo.Order{OrderUID: k})
+	}
+	st := c.Stats()
+	require.Equal(t, 8, st.Shards)
+	require.LessOrEqual(t, st.Size, 1000)
+	require.Greater(t, st.Size, 900, "ключи должны распределяться по шардам примерно равномерно")
+	require.Equal(t, uint64(5000-st.Size), st.Evictions)
+
+	require.Equal(t, 2, cache.New(cache.WithMaxEntries(2), cache.WithShards(16)).Stats().Shards)
+	require.Equal(t, 1, cache.New(cache.WithShards(0)).Stats().Shards)
+}
+
+func TestConcurrent_ShardedMixed(t *testing.T) {
+	c := cache.New(cache.WithMaxEntries(100))
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				k := fmt.Sprintf("k%d", (i*7+w)%300)
+				switch i % 4 {
+				case 0:
+					c.Set(k, repo.Order{OrderUID: k})
+				case 1:
+					c.Delete(k)
+				default:
+					if o, ok := c.Get(k); ok && o.OrderUID != k {
+						t.Errorf("got %s for key %s", o.OrderUID, k)
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	require.LessOrEqual(t, c.Len(), 100+16)
+	_ = c.Stats()
+}
+
+func benchmarkMixed(b *testing.B, c *cache.OrdersCache) {
+	const keys = 4096
+	uids := make([]string, keys)
+	for i := range uids {
+		uids[i] = fmt.Sprintf("order-%d", i)
+		c.Set(uids[i], repo.Order{OrderUID: uids[i]})
+	}
+	var seq atomic.Uint64
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := seq.Add(1) * 7919
+		for pb.Next() {
+			i++
+			k := uids[i%keys]
+			if i%10 == 0 {
+				c.Set(k, repo.Order{OrderUID: k})
+			} else {
+				c.Get(k)
+			}
+		}
+	})
+}
+
+func BenchmarkCache_Mixed90Read_SingleLock(b *testing.B) {
+	benchmarkMixed(b, cache.New(cache.WithShards(1)))
+}
+
+func BenchmarkCache_Mixed90Read_Sharded(b *testing.B) {
+	benchmarkMixed(b, cache.New())
+}
@@ -0,0 +1,265 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/mrussa/L0/internal/repo"
+)
+
+const (
+	defaultRedisPrefix  = "order:"
+	defaultRedisPool    = 8
+	defaultRedisTimeout = 500 * time.Millisecond
+	redisScanCount      = "500"
+	redisMaxScanRounds  = 100000
+	redisDelChunk       = 500
+)
+
+type RedisCache struct {
+	addr     string
+	password string
+	db       int
+	prefix   string
+	ttl      time.Duration
+	timeout  time.Duration
+	log      *slog.Logger
+
+	pool chan *respConn
+
+	hits, misses, errs atomic.Uint64
+}
+
+type RedisOption func(*RedisCache)
+
+func WithRedisPassword(p string) RedisOption {
+	return func(r *RedisCache) { r.password = p }
+}
+
+func WithRedisDB(db int) RedisOption {
+	return func(r *RedisCache) { r.db = db }
+}
+
+func WithRedisPrefix(p string) RedisOption {
+	return func(r *RedisCache) { r.prefix = p }
+}
+
+func WithRedisTTL(d time.Duration) RedisOption {
+	return func(r *RedisCache) { r.ttl = d }
+}
+
+func WithRedisPoolSize(n int) RedisOption {
+	return func(r *RedisCache) {
+		if n > 0 {
+			r.pool = make(chan *respConn, n)
+		}
+	}
+}
+
+func WithRedisTimeout(d time.Duration) RedisOption {
+	return func(r *RedisCache) { r.timeout = d }
+}
+
+func WithRedisLogger(l *slog.Logger) RedisOption {
+	return func(r *RedisCache) { r.log = l }
+}
+
+func NewRedis(addr string, opts ...RedisOption) (*RedisCache, error) {
+	r := &RedisCache{
+		addr:    addr,
+		prefix:  defaultRedisPrefix,
+		timeout: defaultRedisTimeout,
+		log:     slog.New(slog.DiscardHandler),
+		pool:    make(chan *respConn, defaultRedisPool),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	if _, err := r.do("PING"); err != nil {
+		return nil, fmt.Errorf("redis ping %s: %w", addr, err)
+	}
+	return r, nil
+}
+
+func (r *RedisCache) Get(uid string) (repo.Order, bool) {
+	v, err := r.do("GET", r.prefix+uid)
+	if errors.Is(err, errNil) {
+		r.misses.Add(1)
+		return repo.Order{}, false
+	}
+	if err != nil {
+		r.fail("get", uid, err)
+		r.misses.Add(1)
+		return repo.Order{}, false
+	}
+	b, _ := v.([]byte)
+	var o repo.Order
+	if err := json.Unmarshal(b, &o); err != nil {
+		r.fail("decode", uid, err)
+		r.misses.Add(1)
+		return repo.Order{}, false
+	}
+	r.hits.Add(1)
+	return o, true
+}
+
+func (r *RedisCache) Set(uid string, o repo.Order) {
+	b, err := json.Marshal(o)
+	if err != nil {
+		r.fail("encode", uid, err)
+		return
+	}
+	args := []string{"SET", r.prefix + uid, string(b)}
+	if r.ttl > 0 {
+		args = append(args, "PX", strconv.FormatInt(r.ttl.Milliseconds(), 10))
+	}
+	if _, err := r.do(args...); err != nil {
+		r.fail("set", uid, err)
+	}
+}
+
+func (r *RedisCache) Delete(uid string) {
+	if _, err := r.do("DEL", r.prefix+uid); err != nil {
+		r.fail("del", uid, err)
+	}
+}
+
+// Len does not count keys: a SCAN over the keyspace is too expensive for
+// health probes and metric scrapes.
+func (r *RedisCache) Len() int {
+	return SizeUnknown
+}
+
+func (r *RedisCache) Purge() int {
+	var keys []string
+	if err := r.scan(func(batch []string) error {
+		keys = append(keys, batch...)
+		return nil
+	}); err != nil {
+		r.fail("purge", "*", err)
+		return 0
+	}
+
+	n := 0
+	for start := 0; start < len(keys); start += redisDelChunk {
+		chunk := keys[start:min(start+redisDelChunk, len(keys))]
+		v, err := r.do(append([]string{"DEL"}, chunk...)...)
+		if err != nil {
+			r.fail("purge", "*", err)
+			break
+		}
+		if d, ok := v.(int64); ok {
+			n += int(d)
+		}
+	}
+	return n
+}
+
+func (r *RedisCache) Stats() Stats {
+	st := Stats{
+		Backend: "redis",
+		Size:    SizeUnknown,
+		Hits:    r.hits.Load(),
+		Misses:  r.misses.Load(),
+		Errors:  r.errs.Load(),
+	}
+	if total := st.Hits + st.Misses; total > 0 {
+		st.HitRatio = float64(st.Hits) / float64(total)
+	}
+	return st
+}
+
+func (r *RedisCache) Close() error {
+	for {
+		select {
+		case c := <-r.pool:
+			_ = c.Close()
+		default:
+			return nil
+		}
+	}
+}
+
+func (r *RedisCache) scan(fn func(keys []string) error) error {
+	cursor := "0"
+	for range redisMaxScanRounds {
+		v, err := r.do("SCAN", cursor, "MATCH", r.prefix+"*", "COUNT", redisScanCount)
+		if err != nil {
+			return err
+		}
+		arr, ok := v.([]any)
+		if !ok || len(arr) != 2 {
+			return fmt.Errorf("redis: unexpected SCAN reply %T", v)
+		}
+		next, _ := arr[0].([]byte)
+		items, _ := arr[1].([]any)
+		keys := make([]string, 0, len(items))
+		for _, it := range items {
+			if b, ok := it.([]byte); ok {
+				keys = append(keys, string(b))
+			}
+		}
+		if err := fn(keys); err != nil {
+			return err
+		}
+		cursor = string(next)
+		if cursor == "0" || cursor == "" {
+			return nil
+		}
+	}
+	return errors.New("redis: SCAN did not finish")
+}
+
+func (r *RedisCache) do(args ...string) (any, error) {
+	c, err := r.conn()
+	if err != nil {
+		return nil, err
+	}
+	v, err := c.do(r.timeout, args...)
+	var rerr redisError
+	if err != nil && !errors.Is(err, errNil) && !errors.As(err, &rerr) {
+		_ = c.Close()
+		return nil, err
+	}
+	select {
+	case r.pool <- c:
+	default:
+		_ = c.Close()
+	}
+	return v, err
+}
+
+func (r *RedisCache) conn() (*respConn, error) {
+	select {
+	case c := <-r.pool:
+		return c, nil
+	default:
+	}
+	c, err := dialResp(r.addr, r.timeout)
+	if err != nil {
+		return nil, err
+	}
+	if r.password != "" {
+		if _, err := c.do(r.timeout, "AUTH", r.password); err != nil {
+			_ = c.Close()
+			return nil, err
+		}
+	}
+	if r.db != 0 {
+		if _, err := c.do(r.timeout, "SELECT", strconv.Itoa(r.db)); err != nil {
+			_ = c.Close()
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+func (r *RedisCache) fail(op, uid string, err error) {
+	r.errs.Add(1)
+	r.log.Warn("redis op failed", "op", op, "order_uid", uid, "err", err)
+}
@@ -0,0 +1,311 @@
+package cache_test
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrussa/L0/internal/cache"
+	"github.com/mrussa/L0/internal/repo"
+)
+
+type fakeRedis struct {
+	ln       net.Listener
+	password string
+
+	mu    sync.Mutex
+	data  map[string]string
+	ttl   map[string]string
+	cmds  []string
+	conns int
+}
+
+func newFakeRedis(t *testing.T, password string) *fakeRedis {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	f := &fakeRedis{ln: ln, password: password, data: map[string]string{}, ttl: map[string]string{}}
+	t.Cleanup(func() { _ = ln.Close() })
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			f.mu.Lock()
+			f.conns++
+			f.mu.Unlock()
+			go f.serve(c)
+		}
+	}()
+	return f
+}
+
+func (f *fakeRedis) addr() string { return f.ln.Addr().String() }
+
+func (f *fakeRedis) serve(c net.Conn) {
+	defer c.Close()
+	r := bufio.NewReader(c)
+	authed := f.password == ""
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+		f.mu.Lock()
+		f.cmds = append(f.cmds, strings.ToUpper(args[0]))
+		f.mu.Unlock()
+
+		if !authed && strings.ToUpper(args[0]) != "AUTH" {
+			io.WriteString(c, "-NOAUTH Authentication required.\r\n")
+			continue
+		}
+		switch strings.ToUpper(args[0]) {
+		case "AUTH":
+			if args[1] != f.password {
+				io.WriteString(c, "-WRONGPASS invalid password\r\n")
+				continue
+			}
+			authed = true
+			io.WriteString(c, "+OK\r\n")
+		case "PING", "SELECT":
+			io.WriteString(c, "+OK\r\n")
+		case "GET":
+			f.mu.Lock()
+			v, ok := f.data[args[1]]
+			f.mu.Unlock()
+			if !ok {
+				io.WriteString(c, "$-1\r\n")
+				continue
+			}
+			fmt.Fprintf(c, "$%d\r\n%s\r\n", len(v), v)
+		case "SET":
+			f.mu.Lock()
+			f.data[args[1]] = args[2]
+			if len(args) == 5 {
+				f.ttl[args[1]] = args[4]
+			}
+			f.mu.Unlock()
+			io.WriteString(c, "+OK\r\n")
+		case "DEL":
+			n := 0
+			f.mu.Lock()
+			for _, k := range args[1:] {
+				if _, ok := f.data[k]; ok {
+					delete(f.data, k)
+					n++
+				}
+			}
+			f.mu.Unlock()
+			fmt.Fprintf(c, ":%d\r\n", n)
+		case "SCAN":
+			f.scan(c, args)
+		default:
+			fmt.Fprintf(c, "-ERR unknown command '%s'\r\n", args[0])
+		}
+	}
+}
+
+func (f *fakeRedis) scan(c net.Conn, args []string) {
+	cursor, _ := strconv.Atoi(args[1])
+	pattern := args[3]
+	f.mu.Lock()
+	var keys []string
+	for k := range f.data {
+		if ok, _ := path.Match(pattern, k); ok {
+			keys = append(keys, k)
+		}
+	}
+	f.mu.Unlock()
+	sort.Strings(keys)
+
+	const page = 2
+	end := min(cursor+page, len(keys))
+	next := end
+	if end >= len(keys) {
+		next = 0
+	}
+	nextS := strconv.Itoa(next)
+	fmt.Fprintf(c, "*2\r\n$%d\r\n%s\r\n*%d\r\n", len(nextS), nextS, end-cursor)
+	for _, k := range keys[cursor:end] {
+		fmt.Fprintf(c, "$%d\r\n%s\r\n", len(k), k)
+	}
+}
+
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, n)
+	for i := range args {
+		hdr, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, _ := strconv.Atoi(strings.TrimSpace(hdr[1:]))
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:size])
+	}
+	return args, nil
+}
+
+func TestRedis_SetGetDelete_RoundTrip(t *testing.T) {
+	t.Parallel()
+	srv := newFakeRedis(t, "")
+	c, err := cache.NewRedis(srv.addr(), cache.WithRedisTTL(90*time.Second))
+	require.NoError(t, err)
+	defer c.Close()
+
+	want := repo.Order{
+		OrderUID:    "u1",
+		DateCreated: time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC),
+		Payment:     repo.Payment{Amount: 1817, Currency: "USD"},
+		Items:       []repo.Item{{ChrtID: 9934930, Name: "Mascaras"}},
+	}
+	c.Set("u1", want)
+
+	srv.mu.Lock()
+	require.Contains(t, srv.data, "order:u1")
+	require.Equal(t, "90000", srv.ttl["order:u1"])
+	srv.mu.Unlock()
+
+	got, ok := c.Get("u1")
+	require.True(t, ok)
+	require.Equal(t, want, got)
+
+	_, ok = c.Get("missing")
+	require.False(t, ok)
+
+	c.Delete("u1")
+	_, ok = c.Get("u1")
+	require.False(t, ok)
+
+	st := c.Stats()
+	require.Equal(t, "redis", st.Backend)
+	require.Equal(t, uint64(1), st.Hits)
+	require.Equal(t, uint64(2), st.Misses)
+	require.Zero(t, st.Errors)
+}
+
+func TestRedis_Purge_ScanOnlyPrefix(t *testing.T) {
+	t.Parallel()
+	srv := newFakeRedis(t, "")
+	srv.data["foreign"] = "keep"
+	c, err := cache.NewRedis(srv.addr(), cache.WithRedisPrefix("l0:"))
+	require.NoError(t, err)
+
+	for i := 0; i < 5; i++ {
+		k := fmt.Sprintf("u%d", i)
+		c.Set(k, repo.Order{OrderUID: k})
+	}
+	srv.mu.Lock()
+	require.Len(t, srv.data, 6)
+	srv.mu.Unlock()
+
+	require.Equal(t, 5, c.Purge())
+
+	srv.mu.Lock()
+	require.Equal(t, map[string]string{"foreign": "keep"}, srv.data)
+	srv.mu.Unlock()
+}
+
+func TestRedis_LenIsUnknown_NoScan(t *testing.T) {
+	t.Parallel()
+	srv := newFakeRedis(t, "")
+	c, err := cache.NewRedis(srv.addr())
+	require.NoError(t, err)
+	c.Set("u1", repo.Order{OrderUID: "u1"})
+
+	require.Equal(t, cache.SizeUnknown, c.Len())
+	require.Equal(t, cache.SizeUnknown, c.Stats().Size)
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	require.NotContains(t, srv.cmds, "SCAN", "размер не должен считаться обходом ключей")
+}
+
+func TestRedis_AuthAndPoolReuse(t *testing.T) {
+	t.Parallel()
+	srv := newFakeRedis(t, "s3cret")
+
+	_, err := cache.NewRedis(srv.addr(), cache.WithRedisPassword("wrong"))
+	require.ErrorContains(t, err, "WRONGPASS")
+
+	c, err := cache.NewRedis(srv.addr(), cache.WithRedisPassword("s3cret"), cache.WithRedisDB(2), cache.WithRedisPoolSize(1))
+	require.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		c.Set("u", repo.Order{OrderUID: "u"})
+	}
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	require.Equal(t, 2, srv.conns, "последовательные команды должны переиспользовать соединение")
+	require.Contains(t, srv.cmds, "SELECT")
+}
+
+func TestRedis_ServerDown_DegradesToMiss(t *testing.T) {
+	t.Parallel()
+	srv := newFakeRedis(t, "")
+	var logged lockedBuffer
+	c, err := cache.NewRedis(srv.addr(), cache.WithRedisTimeout(200*time.Millisecond),
+		cache.WithRedisLogger(slog.New(slog.NewTextHandler(&logged, nil))))
+	require.NoError(t, err)
+
+	srv.mu.Lock()
+	srv.data["order:bad"] = "{not json"
+	srv.mu.Unlock()
+	_, ok := c.Get("bad")
+	require.False(t, ok)
+
+	require.NoError(t, srv.ln.Close())
+	require.NoError(t, c.Close())
+
+	_, ok = c.Get("u1")
+	require.False(t, ok)
+	c.Set("u1", repo.Order{OrderUID: "u1"})
+	require.Equal(t, 0, c.Purge())
+
+	st := c.Stats()
+	require.GreaterOrEqual(t, st.Errors, uint64(4))
+	require.Contains(t, logged.String(), "msg=\"redis op failed\" op=get order_uid=u1")
+
+	_, err = cache.NewRedis(srv.addr())
+	require.Error(t, err)
+}
+
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
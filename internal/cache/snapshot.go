@@ -0,0 +1,136 @@
+package cache
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/mrussa/L0/internal/repo"
+)
+
+const snapshotVersion = 1
+
+var (
+	ErrSnapshotVersion = errors.New("snapshot schema version mismatch")
+	ErrSnapshotStale   = errors.New("snapshot is stale")
+)
+
+type snapshotHeader struct {
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	Count     int       `json:"count"`
+}
+
+type snapshotEntry struct {
+	UID     string     `json:"uid"`
+	Expires time.Time  `json:"expires,omitzero"`
+	Order   repo.Order `json:"order"`
+}
+
+func (c *OrdersCache) SaveSnapshot(path string) (int, error) {
+	entries := c.snapshotEntries()
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return 0, fmt.Errorf("snapshot create: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := writeSnapshot(tmp, c.now(), entries); err != nil {
+		_ = tmp.Close()
+		return 0, fmt.Errorf("snapshot write: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return 0, fmt.Errorf("snapshot close: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return 0, fmt.Errorf("snapshot rename: %w", err)
+	}
+	return len(entries), nil
+}
+
+func (c *OrdersCache) LoadSnapshot(path string, maxAge time.Duration) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("snapshot open: %w", err)
+	}
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return 0, fmt.Errorf("snapshot gzip: %w", err)
+	}
+	defer zr.Close()
+
+	dec := json.NewDecoder(bufio.NewReader(zr))
+	var hdr snapshotHeader
+	if err := dec.Decode(&hdr); err != nil {
+		return 0, fmt.Errorf("snapshot header: %w", err)
+	}
+	if hdr.Version != snapshotVersion {
+		return 0, fmt.Errorf("%w: got %d, want %d", ErrSnapshotVersion, hdr.Version, snapshotVersion)
+	}
+	now := c.now()
+	if age := now.Sub(hdr.CreatedAt); maxAge > 0 && age > maxAge {
+		return 0, fmt.Errorf("%w: age %s > %s", ErrSnapshotStale, age.Round(time.Second), maxAge)
+	}
+
+	loaded := make([]snapshotEntry, 0, hdr.Count)
+	for {
+		var e snapshotEntry
+		err := dec.Decode(&e)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return 0, fmt.Errorf("snapshot entry %d: %w", len(loaded), err)
+		}
+		loaded = append(loaded, e)
+	}
+	if len(loaded) != hdr.Count {
+		return 0, fmt.Errorf("snapshot truncated: %d of %d entries", len(loaded), hdr.Count)
+	}
+
+	n := 0
+	for _, e := range loaded {
+		if !e.Expires.IsZero() && !now.Before(e.Expires) {
+			continue
+		}
+		c.shardFor(e.UID).put(e.UID, e.Order, e.Expires)
+		n++
+	}
+	return n, nil
+}
+
+func (c *OrdersCache) snapshotEntries() []snapshotEntry {
+	var out []snapshotEntry
+	for _, s := range c.shards {
+		s.mu.Lock()
+		for el := s.lru.Back(); el != nil; el = el.Prev() {
+			e := el.Value.(*entry)
+			out = append(out, snapshotEntry{UID: e.uid, Expires: e.expires, Order: e.order})
+		}
+		s.mu.Unlock()
+	}
+	return out
+}
+
+func writeSnapshot(w io.Writer, now time.Time, entries []snapshotEntry) error {
+	zw := gzip.NewWriter(w)
+	enc := json.NewEncoder(zw)
+	if err := enc.Encode(snapshotHeader{Version: snapshotVersion, CreatedAt: now.UTC(), Count: len(entries)}); err != nil {
+		return err
+	}
+	for i := range entries {
+		if err := enc.Encode(&entries[i]); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
@@ -0,0 +1,123 @@
+package cache_test
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrussa/L0/internal/cache"
+	"github.com/mrussa/L0/internal/repo"
+)
+
+func writeRawSnapshot(t *testing.T, path string, lines ...any) {
+	t.Helper()
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	zw := gzip.NewWriter(f)
+	enc := json.NewEncoder(zw)
+	for _, l := range lines {
+		require.NoError(t, enc.Encode(l))
+	}
+	require.NoError(t, zw.Close())
+	require.NoError(t, f.Close())
+}
+
+func TestSnapshot_RoundTrip_PreservesRecency(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "cache.snap.gz")
+
+	src := cache.New(cache.WithShards(1))
+	want := repo.Order{OrderUID: "a", Items: []repo.Item{{ChrtID: 1, Name: "x"}}, DateCreated: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}
+	src.Set("a", want)
+	src.Set("b", repo.Order{OrderUID: "b"})
+	src.Get("a")
+
+	n, err := src.SaveSnapshot(path)
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+
+	dst := cache.New(cache.WithShards(1), cache.WithMaxEntries(2))
+	n, err = dst.LoadSnapshot(path, time.Hour)
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+
+	dst.Set("c", repo.Order{OrderUID: "c"})
+	_, ok := dst.Get("b")
+	require.False(t, ok, "порядок LRU должен сохраниться: b использовался раньше a")
+	got, ok := dst.Get("a")
+	require.True(t, ok)
+	require.Equal(t, want, got)
+
+	matches, _ := filepath.Glob(path + ".tmp-*")
+	require.Empty(t, matches, "временные файлы не должны оставаться")
+}
+
+func TestSnapshot_SkipsExpiredEntries(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "cache.snap.gz")
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	src := cache.New(cache.WithTTL(time.Minute), cache.WithClock(clock))
+	src.Set("old", repo.Order{OrderUID: "old"})
+	now = now.Add(30 * time.Second)
+	src.Set("fresh", repo.Order{OrderUID: "fresh"})
+	_, err := src.SaveSnapshot(path)
+	require.NoError(t, err)
+
+	now = now.Add(45 * time.Second)
+	dst := cache.New(cache.WithTTL(time.Minute), cache.WithClock(clock))
+	n, err := dst.LoadSnapshot(path, time.Hour)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	_, ok := dst.Get("fresh")
+	require.True(t, ok)
+}
+
+func TestSnapshot_Rejects_MissingStaleVersionTruncated(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	c := cache.New(cache.WithClock(func() time.Time { return now }))
+
+	_, err := c.LoadSnapshot(filepath.Join(dir, "nope"), time.Hour)
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	stale := filepath.Join(dir, "stale")
+	writeRawSnapshot(t, stale, map[string]any{"version": 1, "created_at": now.Add(-2 * time.Hour), "count": 0})
+	_, err = c.LoadSnapshot(stale, time.Hour)
+	require.ErrorIs(t, err, cache.ErrSnapshotStale)
+	_, err = c.LoadSnapshot(stale, 0)
+	require.NoError(t, err, "maxAge=0 отключает проверку возраста")
+
+	future := filepath.Join(dir, "v99")
+	writeRawSnapshot(t, future, map[string]any{"version": 99, "created_at": now, "count": 0})
+	_, err = c.LoadSnapshot(future, time.Hour)
+	require.ErrorIs(t, err, cache.ErrSnapshotVersion)
+
+	short := filepath.Join(dir, "short")
+	writeRawSnapshot(t, short,
+		map[string]any{"version": 1, "created_at": now, "count": 2},
+		map[string]any{"uid": "a", "order": map[string]any{"order_uid": "a"}},
+	)
+	_, err = c.LoadSnapshot(short, time.Hour)
+	require.ErrorContains(t, err, "truncated")
+	require.Equal(t, 0, c.Len(), "битый снапшот не должен частично загружаться")
+
+	plain := filepath.Join(dir, "plain")
+	require.NoError(t, os.WriteFile(plain, []byte("not gzip"), 0o600))
+	_, err = c.LoadSnapshot(plain, time.Hour)
+	require.ErrorContains(t, err, "snapshot gzip")
+}
+
+func TestSnapshot_SaveToMissingDirFails(t *testing.T) {
+	t.Parallel()
+	c := cache.New()
+	_, err := c.SaveSnapshot(filepath.Join(t.TempDir(), "no", "such", "dir", "snap"))
+	require.ErrorContains(t, err, "snapshot create")
+}
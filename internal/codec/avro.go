@@ -0,0 +1,72 @@
+package codec
+
+import (
+	"context"
+	_ "embed"
+	"encoding/binary"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/hamba/avro/v2"
+	"github.com/hamba/avro/v2/registry"
+
+	"github.com/mrussa/L0/internal/repo"
+)
+
+const registryTimeout = 5 * time.Second
+
+//go:embed order.avsc
+var orderSchemaJSON string
+
+var (
+	OrderSchema = avro.MustParse(orderSchemaJSON)
+	avroAPI     = avro.Config{TagKey: "json"}.Freeze()
+)
+
+type Avro struct {
+	schemas *registry.Decoder
+	timeout time.Duration
+}
+
+func NewAvro(registryURL string, client *http.Client) (*Avro, error) {
+	a := &Avro{timeout: registryTimeout}
+	if registryURL == "" {
+		return a, nil
+	}
+	opts := []registry.ClientFunc{}
+	if client != nil {
+		opts = append(opts, registry.WithHTTPClient(client))
+	}
+	rc, err := registry.NewClient(registryURL, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("schema registry: %w", err)
+	}
+	a.schemas = registry.NewDecoder(rc, registry.WithAPI(avroAPI))
+	return a, nil
+}
+
+func (a *Avro) Decode(b []byte, o *repo.Order) error {
+	var err error
+	if a.schemas != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), a.timeout)
+		defer cancel()
+		err = a.schemas.Decode(ctx, b, o)
+	} else {
+		err = avroAPI.Unmarshal(OrderSchema, b, o)
+	}
+	if err != nil {
+		return fmt.Errorf("avro: %w", err)
+	}
+	return nil
+}
+
+func MarshalAvro(o repo.Order) ([]byte, error) {
+	return avroAPI.Marshal(OrderSchema, o)
+}
+
+func WireFormat(schemaID int, payload []byte) []byte {
+	b := make([]byte, 5, 5+len(payload))
+	binary.BigEndian.PutUint32(b[1:], uint32(schemaID))
+	return append(b, payload...)
+}
@@ -0,0 +1,107 @@
+package codec_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrussa/L0/internal/codec"
+	"github.com/mrussa/L0/internal/repo"
+)
+
+func newRegistry(t *testing.T, schemas map[string]string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+		s, ok := schemas[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(map[string]any{"error_code": 40403, "message": "Schema not found"})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"schema": s})
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &hits
+}
+
+func readSchema(t *testing.T) string {
+	t.Helper()
+	b, err := os.ReadFile("order.avsc")
+	require.NoError(t, err)
+	return string(b)
+}
+
+func TestAvro_LocalSchema(t *testing.T) {
+	o := fixtureOrder(t)
+	b, err := codec.MarshalAvro(o)
+	require.NoError(t, err)
+
+	a, err := codec.NewAvro("", nil)
+	require.NoError(t, err)
+	var got repo.Order
+	require.NoError(t, a.Decode(b, &got))
+	require.Equal(t, o, got)
+}
+
+func TestAvro_Registry_WireFormat(t *testing.T) {
+	o := fixtureOrder(t)
+	payload, err := codec.MarshalAvro(o)
+	require.NoError(t, err)
+
+	var evolved map[string]any
+	require.NoError(t, json.Unmarshal([]byte(readSchema(t)), &evolved))
+	evolved["fields"] = append(evolved["fields"].([]any), map[string]any{"name": "gift_wrap", "type": "boolean", "default": false})
+	evolvedJSON, err := json.Marshal(evolved)
+	require.NoError(t, err)
+
+	srv, hits := newRegistry(t, map[string]string{
+		"/schemas/ids/7": readSchema(t),
+		"/schemas/ids/8": string(evolvedJSON),
+	})
+	a, err := codec.NewAvro(srv.URL, srv.Client())
+	require.NoError(t, err)
+
+	var got repo.Order
+	require.NoError(t, a.Decode(codec.WireFormat(7, payload), &got))
+	require.Equal(t, o, got)
+
+	got = repo.Order{}
+	require.NoError(t, a.Decode(codec.WireFormat(7, payload), &got))
+	require.Equal(t, o, got)
+	require.EqualValues(t, 1, hits.Load(), "схема должна кэшироваться по id")
+
+	got = repo.Order{}
+	withGift := append(append([]byte{}, payload...), 0x01)
+	require.NoError(t, a.Decode(codec.WireFormat(8, withGift), &got), "лишние поля схемы писателя пропускаются")
+	require.Equal(t, o, got)
+}
+
+func TestAvro_Registry_Errors(t *testing.T) {
+	srv, _ := newRegistry(t, map[string]string{})
+	a, err := codec.NewAvro(srv.URL, srv.Client())
+	require.NoError(t, err)
+
+	var o repo.Order
+	err = a.Decode(codec.WireFormat(42, []byte{0x02, 'x'}), &o)
+	require.ErrorContains(t, err, "avro:")
+	require.ErrorContains(t, err, "Schema not found")
+
+	err = a.Decode([]byte{0x01, 0, 0, 0, 1, 0x02}, &o)
+	require.ErrorContains(t, err, "magic byte")
+
+	err = a.Decode([]byte{0x00, 0x01}, &o)
+	require.ErrorContains(t, err, "too short")
+}
+
+func TestAvro_BadRegistryURL(t *testing.T) {
+	_, err := codec.NewAvro("http://[::1", nil)
+	require.ErrorContains(t, err, "schema registry")
+}
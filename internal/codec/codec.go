@@ -0,0 +1,51 @@
+package codec
+
+import (
+	"encoding/json"
+	"errors"
+	"mime"
+	"strings"
+
+	"github.com/mrussa/L0/internal/repo"
+)
+
+type Format string
+
+const (
+	FormatJSON     Format = "json"
+	FormatProtobuf Format = "protobuf"
+	FormatAvro     Format = "avro"
+)
+
+var ErrUnknownFormat = errors.New("unknown message format")
+
+var contentTypes = map[string]Format{
+	"application/json":                   FormatJSON,
+	"application/x-protobuf":             FormatProtobuf,
+	"application/protobuf":               FormatProtobuf,
+	"application/vnd.google.protobuf":    FormatProtobuf,
+	"application/avro":                   FormatAvro,
+	"avro/binary":                        FormatAvro,
+	"application/vnd.apache.avro+binary": FormatAvro,
+}
+
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(strings.TrimSpace(s))); f {
+	case FormatJSON, FormatProtobuf, FormatAvro:
+		return f, nil
+	}
+	return "", ErrUnknownFormat
+}
+
+func FormatOf(contentType string) (Format, error) {
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", ErrUnknownFormat
+	}
+	if f, ok := contentTypes[mt]; ok {
+		return f, nil
+	}
+	return "", ErrUnknownFormat
+}
+
+func JSON(b []byte, o *repo.Order) error { return json.Unmarshal(b, o) }
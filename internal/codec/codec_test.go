@@ -0,0 +1,70 @@
+package codec_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrussa/L0/internal/codec"
+	"github.com/mrussa/L0/internal/repo"
+)
+
+func fixtureOrder(t *testing.T) repo.Order {
+	t.Helper()
+	b, err := os.ReadFile("../../fixtures/model.json")
+	require.NoError(t, err)
+	var o repo.Order
+	require.NoError(t, codec.JSON(b, &o))
+	return o
+}
+
+func TestParseFormat(t *testing.T) {
+	for in, want := range map[string]codec.Format{
+		"json":     codec.FormatJSON,
+		"Protobuf": codec.FormatProtobuf,
+		" avro ":   codec.FormatAvro,
+	} {
+		got, err := codec.ParseFormat(in)
+		require.NoError(t, err, in)
+		require.Equal(t, want, got)
+	}
+	_, err := codec.ParseFormat("xml")
+	require.ErrorIs(t, err, codec.ErrUnknownFormat)
+}
+
+func TestFormatOf(t *testing.T) {
+	for ct, want := range map[string]codec.Format{
+		"application/json; charset=utf-8": codec.FormatJSON,
+		"application/x-protobuf":          codec.FormatProtobuf,
+		"application/protobuf":            codec.FormatProtobuf,
+		"Application/Avro":                codec.FormatAvro,
+		"avro/binary":                     codec.FormatAvro,
+	} {
+		got, err := codec.FormatOf(ct)
+		require.NoError(t, err, ct)
+		require.Equal(t, want, got, ct)
+	}
+	for _, ct := range []string{"", "text/plain", ";;"} {
+		_, err := codec.FormatOf(ct)
+		require.ErrorIs(t, err, codec.ErrUnknownFormat, ct)
+	}
+}
+
+func TestProtobuf_RoundTrip(t *testing.T) {
+	o := fixtureOrder(t)
+	b, err := codec.MarshalProtobuf(o)
+	require.NoError(t, err)
+
+	var got repo.Order
+	require.NoError(t, codec.Protobuf(b, &got))
+	require.Equal(t, o, got)
+	require.Equal(t, time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC), got.DateCreated)
+}
+
+func TestProtobuf_Garbage(t *testing.T) {
+	var o repo.Order
+	err := codec.Protobuf([]byte{0xff, 0xff, 0xff}, &o)
+	require.ErrorContains(t, err, "protobuf:")
+}
@@ -0,0 +1,138 @@
+package codec
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/mrussa/L0/internal/codec/orderpb"
+	"github.com/mrussa/L0/internal/repo"
+)
+
+func Protobuf(b []byte, o *repo.Order) error {
+	var pb orderpb.Order
+	if err := proto.Unmarshal(b, &pb); err != nil {
+		return fmt.Errorf("protobuf: %w", err)
+	}
+	*o = fromProto(&pb)
+	return nil
+}
+
+func MarshalProtobuf(o repo.Order) ([]byte, error) {
+	return proto.Marshal(toProto(o))
+}
+
+func fromProto(pb *orderpb.Order) repo.Order {
+	o := repo.Order{
+		OrderUID:          pb.GetOrderUid(),
+		TrackNumber:       pb.GetTrackNumber(),
+		Entry:             pb.GetEntry(),
+		Locale:            pb.GetLocale(),
+		InternalSignature: pb.GetInternalSignature(),
+		CustomerID:        pb.GetCustomerId(),
+		DeliveryService:   pb.GetDeliveryService(),
+		ShardKey:          pb.GetShardkey(),
+		SMID:              pb.GetSmId(),
+		OofShard:          pb.GetOofShard(),
+	}
+	if pb.DateCreated != nil {
+		o.DateCreated = pb.DateCreated.AsTime()
+	}
+	if d := pb.GetDelivery(); d != nil {
+		o.Delivery = repo.Delivery{
+			Name:    d.GetName(),
+			Phone:   d.GetPhone(),
+			Zip:     d.GetZip(),
+			City:    d.GetCity(),
+			Address: d.GetAddress(),
+			Region:  d.GetRegion(),
+			Email:   d.GetEmail(),
+		}
+	}
+	if p := pb.GetPayment(); p != nil {
+		o.Payment = repo.Payment{
+			TransactionID: p.GetTransaction(),
+			RequestID:     p.GetRequestId(),
+			Currency:      p.GetCurrency(),
+			Provider:      p.GetProvider(),
+			Amount:        p.GetAmount(),
+			PaymentDT:     p.GetPaymentDt(),
+			Bank:          p.GetBank(),
+			DeliveryCost:  p.GetDeliveryCost(),
+			GoodsTotal:    p.GetGoodsTotal(),
+			CustomFee:     p.GetCustomFee(),
+		}
+	}
+	for _, it := range pb.GetItems() {
+		o.Items = append(o.Items, repo.Item{
+			ChrtID:      it.GetChrtId(),
+			TrackNumber: it.GetTrackNumber(),
+			Price:       it.GetPrice(),
+			RID:         it.GetRid(),
+			Name:        it.GetName(),
+			Sale:        it.GetSale(),
+			Size:        it.GetSize(),
+			TotalPrice:  it.GetTotalPrice(),
+			NmID:        it.GetNmId(),
+			Brand:       it.GetBrand(),
+			Status:      it.GetStatus(),
+		})
+	}
+	return o
+}
+
+func toProto(o repo.Order) *orderpb.Order {
+	pb := &orderpb.Order{
+		OrderUid:          o.OrderUID,
+		TrackNumber:       o.TrackNumber,
+		Entry:             o.Entry,
+		Locale:            o.Locale,
+		InternalSignature: o.InternalSignature,
+		CustomerId:        o.CustomerID,
+		DeliveryService:   o.DeliveryService,
+		Shardkey:          o.ShardKey,
+		SmId:              o.SMID,
+		OofShard:          o.OofShard,
+		Delivery: &orderpb.Delivery{
+			Name:    o.Delivery.Name,
+			Phone:   o.Delivery.Phone,
+			Zip:     o.Delivery.Zip,
+			City:    o.Delivery.City,
+			Address: o.Delivery.Address,
+			Region:  o.Delivery.Region,
+			Email:   o.Delivery.Email,
+		},
+		Payment: &orderpb.Payment{
+			Transaction:  o.Payment.TransactionID,
+			RequestId:    o.Payment.RequestID,
+			Currency:     o.Payment.Currency,
+			Provider:     o.Payment.Provider,
+			Amount:       o.Payment.Amount,
+			PaymentDt:    o.Payment.PaymentDT,
+			Bank:         o.Payment.Bank,
+			DeliveryCost: o.Payment.DeliveryCost,
+			GoodsTotal:   o.Payment.GoodsTotal,
+			CustomFee:    o.Payment.CustomFee,
+		},
+	}
+	if !o.DateCreated.IsZero() {
+		pb.DateCreated = timestamppb.New(o.DateCreated)
+	}
+	for _, it := range o.Items {
+		pb.Items = append(pb.Items, &orderpb.Item{
+			ChrtId:      it.ChrtID,
+			TrackNumber: it.TrackNumber,
+			Price:       it.Price,
+			Rid:         it.RID,
+			Name:        it.Name,
+			Sale:        it.Sale,
+			Size:        it.Size,
+			TotalPrice:  it.TotalPrice,
+			NmId:        it.NmID,
+			Brand:       it.Brand,
+			Status:      it.Status,
+		})
+	}
+	return pb
+}
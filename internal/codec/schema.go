@@ -0,0 +1,79 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+
+	"github.com/mrussa/L0/internal/repo"
+)
+
+const (
+	SchemaBuiltin  = "builtin"
+	orderSchemaURL = "https://github.com/mrussa/L0/order.schema.json"
+)
+
+var printer = message.NewPrinter(language.English)
+
+func OrderJSONSchema() map[string]any {
+	s := typeSchema(reflect.TypeOf(repo.Order{}))
+	s["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	s["$id"] = orderSchemaURL
+	s["title"] = "Order"
+	return s
+}
+
+func typeSchema(t reflect.Type) map[string]any {
+	switch {
+	case t == timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case t.Kind() == reflect.Struct:
+		props := map[string]any{}
+		for f := range jsonFieldsSeq(t) {
+			props[f.name] = typeSchema(f.typ)
+		}
+		return map[string]any{"type": "object", "properties": props, "additionalProperties": false}
+	case t.Kind() == reflect.Slice:
+		return map[string]any{"type": []any{"array", "null"}, "items": typeSchema(t.Elem())}
+	case t.Kind() == reflect.String:
+		return map[string]any{"type": "string"}
+	case t.Kind() == reflect.Int32:
+		return map[string]any{"type": "integer", "minimum": math.MinInt32, "maximum": math.MaxInt32}
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Int64:
+		return map[string]any{"type": "integer"}
+	case t.Kind() == reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	}
+	return map[string]any{}
+}
+
+func CompileSchema(source string) (*jsonschema.Schema, error) {
+	c := jsonschema.NewCompiler()
+	c.AssertFormat()
+	loc := source
+	if source == SchemaBuiltin {
+		loc = orderSchemaURL
+		b, err := json.Marshal(OrderJSONSchema())
+		if err != nil {
+			return nil, fmt.Errorf("json schema: %w", err)
+		}
+		doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(b))
+		if err != nil {
+			return nil, fmt.Errorf("json schema: %w", err)
+		}
+		if err := c.AddResource(loc, doc); err != nil {
+			return nil, fmt.Errorf("json schema: %w", err)
+		}
+	}
+	s, err := c.Compile(loc)
+	if err != nil {
+		return nil, fmt.Errorf("json schema: %w", err)
+	}
+	return s, nil
+}
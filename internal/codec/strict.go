@@ -0,0 +1,296 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+
+	"github.com/mrussa/L0/internal/repo"
+)
+
+const maxViolations = 50
+
+type Violation struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+func (v Violation) String() string { return v.Path + ": " + v.Message }
+
+type DecodeError struct {
+	Violations []Violation
+}
+
+func (e *DecodeError) Error() string {
+	const shown = 3
+	var sb strings.Builder
+	sb.WriteString("invalid payload: ")
+	for i, v := range e.Violations {
+		if i == shown {
+			fmt.Fprintf(&sb, " (+%d more)", len(e.Violations)-shown)
+			break
+		}
+		if i > 0 {
+			sb.WriteString("; ")
+		}
+		sb.WriteString(v.String())
+	}
+	return sb.String()
+}
+
+func (e *DecodeError) Strings() []string {
+	out := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		out[i] = v.String()
+	}
+	return out
+}
+
+type JSONDecoder struct {
+	Strict bool
+	Schema *jsonschema.Schema
+}
+
+func (d *JSONDecoder) Decode(b []byte, o *repo.Order) error {
+	if !d.Strict && d.Schema == nil {
+		return json.Unmarshal(b, o)
+	}
+
+	doc, err := parseJSON(b)
+	if err != nil {
+		return &DecodeError{Violations: []Violation{{Path: "$", Message: err.Error()}}}
+	}
+
+	var vs violations
+	if d.Strict {
+		vs.check("$", doc, reflect.TypeOf(*o))
+	}
+	if d.Schema != nil {
+		var verr *jsonschema.ValidationError
+		if err := d.Schema.Validate(doc); errors.As(err, &verr) {
+			vs.addSchema(verr)
+		} else if err != nil {
+			vs.add("$", err.Error())
+		}
+	}
+	if len(vs.list) > 0 {
+		return &DecodeError{Violations: vs.list}
+	}
+	return json.Unmarshal(b, o)
+}
+
+func parseJSON(b []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("malformed JSON: %w", err)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("malformed JSON: trailing data after the document")
+	}
+	return doc, nil
+}
+
+type violations struct {
+	list []Violation
+	seen map[string]bool
+}
+
+func (vs *violations) add(path, msg string) {
+	if len(vs.list) >= maxViolations || vs.seen[path] {
+		return
+	}
+	if vs.seen == nil {
+		vs.seen = map[string]bool{}
+	}
+	vs.seen[path] = true
+	vs.list = append(vs.list, Violation{Path: path, Message: msg})
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func (vs *violations) check(path string, v any, t reflect.Type) {
+	if v == nil {
+		return
+	}
+	switch {
+	case t == timeType:
+		s, ok := v.(string)
+		if !ok {
+			vs.add(path, mismatch("RFC 3339 time string", v))
+			return
+		}
+		if _, err := time.Parse(time.RFC3339, s); err != nil {
+			vs.add(path, fmt.Sprintf("invalid RFC 3339 time %q", s))
+		}
+	case t.Kind() == reflect.Struct:
+		obj, ok := v.(map[string]any)
+		if !ok {
+			vs.add(path, mismatch("object", v))
+			return
+		}
+		fields := jsonFields(t)
+		for _, k := range sortedKeys(obj) {
+			ft, ok := fields[k]
+			if !ok {
+				vs.add(childPath(path, k), "unknown field")
+				continue
+			}
+			vs.check(childPath(path, k), obj[k], ft)
+		}
+	case t.Kind() == reflect.Slice:
+		arr, ok := v.([]any)
+		if !ok {
+			vs.add(path, mismatch("array", v))
+			return
+		}
+		for i, el := range arr {
+			vs.check(path+"["+strconv.Itoa(i)+"]", el, t.Elem())
+		}
+	case t.Kind() == reflect.String:
+		if _, ok := v.(string); !ok {
+			vs.add(path, mismatch("string", v))
+		}
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Int64:
+		n, ok := v.(json.Number)
+		if !ok {
+			vs.add(path, mismatch("integer", v))
+			return
+		}
+		i, err := n.Int64()
+		if err != nil {
+			vs.add(path, fmt.Sprintf("want integer, got %s", n))
+			return
+		}
+		if reflect.Zero(t).OverflowInt(i) {
+			vs.add(path, fmt.Sprintf("%d overflows %s", i, t.Kind()))
+		}
+	case t.Kind() == reflect.Bool:
+		if _, ok := v.(bool); !ok {
+			vs.add(path, mismatch("boolean", v))
+		}
+	}
+}
+
+func (vs *violations) addSchema(verr *jsonschema.ValidationError) {
+	var leaves []Violation
+	var walk func(*jsonschema.ValidationError)
+	walk = func(e *jsonschema.ValidationError) {
+		if len(e.Causes) == 0 {
+			leaves = append(leaves, Violation{pointerPath(e.InstanceLocation), e.ErrorKind.LocalizedString(printer)})
+			return
+		}
+		for _, c := range e.Causes {
+			walk(c)
+		}
+	}
+	walk(verr)
+	sort.SliceStable(leaves, func(i, j int) bool { return leaves[i].Path < leaves[j].Path })
+	for _, v := range leaves {
+		vs.add(v.Path, v.Message)
+	}
+}
+
+func mismatch(want string, got any) string {
+	return fmt.Sprintf("want %s, got %s", want, jsonType(got))
+}
+
+func jsonType(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number, float64:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+var identRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func childPath(base, key string) string {
+	if identRe.MatchString(key) {
+		return base + "." + key
+	}
+	return base + "[" + strconv.Quote(key) + "]"
+}
+
+func pointerPath(tokens []string) string {
+	p := "$"
+	for _, tok := range tokens {
+		if _, err := strconv.Atoi(tok); err == nil {
+			p += "[" + tok + "]"
+			continue
+		}
+		p = childPath(p, tok)
+	}
+	return p
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+var fieldCache sync.Map
+
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	if f, ok := fieldCache.Load(t); ok {
+		return f.(map[string]reflect.Type)
+	}
+	fields := make(map[string]reflect.Type, t.NumField())
+	for f := range jsonFieldsSeq(t) {
+		fields[f.name] = f.typ
+	}
+	fieldCache.Store(t, fields)
+	return fields
+}
+
+type jsonField struct {
+	name string
+	typ  reflect.Type
+}
+
+func jsonFieldsSeq(t reflect.Type) func(func(jsonField) bool) {
+	return func(yield func(jsonField) bool) {
+		for i := range t.NumField() {
+			sf := t.Field(i)
+			if !sf.IsExported() {
+				continue
+			}
+			name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = sf.Name
+			}
+			if !yield(jsonField{name, sf.Type}) {
+				return
+			}
+		}
+	}
+}
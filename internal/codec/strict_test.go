@@ -0,0 +1,183 @@
+package codec_test
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrussa/L0/internal/codec"
+	"github.com/mrussa/L0/internal/repo"
+)
+
+func fixtureDoc(t *testing.T) map[string]any {
+	t.Helper()
+	b, err := os.ReadFile("../../fixtures/model.json")
+	require.NoError(t, err)
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(b, &doc))
+	return doc
+}
+
+func encode(t *testing.T, doc any) []byte {
+	t.Helper()
+	b, err := json.Marshal(doc)
+	require.NoError(t, err)
+	return b
+}
+
+func violations(t *testing.T, err error) []codec.Violation {
+	t.Helper()
+	var de *codec.DecodeError
+	require.True(t, errors.As(err, &de), "ожидалась DecodeError, получено %v", err)
+	return de.Violations
+}
+
+func builtinDecoder(t *testing.T, strict bool) *codec.JSONDecoder {
+	t.Helper()
+	s, err := codec.CompileSchema(codec.SchemaBuiltin)
+	require.NoError(t, err)
+	return &codec.JSONDecoder{Strict: strict, Schema: s}
+}
+
+func TestJSONDecoder_ValidFixture(t *testing.T) {
+	want := fixtureOrder(t)
+	b, err := os.ReadFile("../../fixtures/model.json")
+	require.NoError(t, err)
+
+	for name, d := range map[string]*codec.JSONDecoder{
+		"plain":         {},
+		"strict":        {Strict: true},
+		"strict+schema": builtinDecoder(t, true),
+	} {
+		var got repo.Order
+		require.NoError(t, d.Decode(b, &got), name)
+		require.Equal(t, want, got, name)
+	}
+}
+
+func TestJSONDecoder_Strict_UnknownFields(t *testing.T) {
+	doc := fixtureDoc(t)
+	pay := doc["payment"].(map[string]any)
+	pay["amont"] = pay["amount"]
+	doc["items"].([]any)[0].(map[string]any)["colour"] = "red"
+	doc["weird key"] = true
+	b := encode(t, doc)
+
+	var o repo.Order
+	require.NoError(t, (&codec.JSONDecoder{}).Decode(b, &o), "без strict лишние поля игнорируются")
+
+	err := (&codec.JSONDecoder{Strict: true}).Decode(b, &o)
+	require.Equal(t, []codec.Violation{
+		{Path: "$.items[0].colour", Message: "unknown field"},
+		{Path: "$.payment.amont", Message: "unknown field"},
+		{Path: `$["weird key"]`, Message: "unknown field"},
+	}, violations(t, err))
+}
+
+func TestJSONDecoder_Strict_TypeMismatches(t *testing.T) {
+	doc := fixtureDoc(t)
+	doc["payment"].(map[string]any)["amount"] = "1817"
+	doc["items"].([]any)[0].(map[string]any)["price"] = 4.5
+	doc["sm_id"] = 30000000000
+	doc["date_created"] = "yesterday"
+	doc["delivery"] = []any{}
+	doc["order_uid"] = nil
+
+	var o repo.Order
+	err := (&codec.JSONDecoder{Strict: true}).Decode(encode(t, doc), &o)
+	require.Equal(t, []codec.Violation{
+		{Path: "$.date_created", Message: `invalid RFC 3339 time "yesterday"`},
+		{Path: "$.delivery", Message: "want object, got array"},
+		{Path: "$.items[0].price", Message: "want integer, got 4.5"},
+		{Path: "$.payment.amount", Message: "want integer, got string"},
+		{Path: "$.sm_id", Message: "30000000000 overflows int32"},
+	}, violations(t, err))
+	require.Equal(t, repo.Order{}, o, "при ошибке заказ не заполняется")
+}
+
+func TestJSONDecoder_Malformed(t *testing.T) {
+	d := &codec.JSONDecoder{Strict: true}
+	var o repo.Order
+
+	vs := violations(t, d.Decode([]byte(`{"order_uid":`), &o))
+	require.Len(t, vs, 1)
+	require.Equal(t, "$", vs[0].Path)
+	require.Contains(t, vs[0].Message, "malformed JSON")
+
+	vs = violations(t, d.Decode([]byte(`{} {}`), &o))
+	require.Equal(t, "malformed JSON: trailing data after the document", vs[0].Message)
+}
+
+func TestJSONDecoder_BuiltinSchema(t *testing.T) {
+	doc := fixtureDoc(t)
+	doc["payment"].(map[string]any)["amount"] = "1817"
+	doc["extra"] = 1
+
+	var o repo.Order
+	vs := violations(t, builtinDecoder(t, false).Decode(encode(t, doc), &o))
+	paths := map[string]string{}
+	for _, v := range vs {
+		paths[v.Path] = v.Message
+	}
+	require.Contains(t, paths, "$.payment.amount")
+	require.Contains(t, paths["$.payment.amount"], "want integer")
+	require.Contains(t, paths, "$")
+	require.Contains(t, paths["$"], "extra")
+}
+
+func TestJSONDecoder_SchemaFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "order.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+		"type": "object",
+		"required": ["order_uid", "payment"],
+		"properties": {
+			"order_uid": {"type": "string", "minLength": 1, "maxLength": 100},
+			"payment": {"type": "object", "properties": {"currency": {"enum": ["USD", "EUR", "RUB"]}}}
+		}
+	}`), 0o644))
+	s, err := codec.CompileSchema(path)
+	require.NoError(t, err)
+	d := &codec.JSONDecoder{Strict: true, Schema: s}
+
+	doc := fixtureDoc(t)
+	doc["order_uid"] = ""
+	doc["payment"].(map[string]any)["currency"] = "XXX"
+
+	var o repo.Order
+	vs := violations(t, d.Decode(encode(t, doc), &o))
+	require.Len(t, vs, 2)
+	require.Equal(t, "$.order_uid", vs[0].Path)
+	require.Equal(t, "$.payment.currency", vs[1].Path)
+}
+
+func TestCompileSchema_Missing(t *testing.T) {
+	_, err := codec.CompileSchema(filepath.Join(t.TempDir(), "nope.json"))
+	require.ErrorContains(t, err, "json schema")
+}
+
+func TestOrderJSONSchema(t *testing.T) {
+	s := codec.OrderJSONSchema()
+	require.Equal(t, "object", s["type"])
+	require.Equal(t, false, s["additionalProperties"])
+	props := s["properties"].(map[string]any)
+	require.Equal(t, map[string]any{"type": "string", "format": "date-time"}, props["date_created"])
+	pay := props["payment"].(map[string]any)["properties"].(map[string]any)
+	require.Equal(t, "integer", pay["amount"].(map[string]any)["type"])
+	require.Contains(t, pay, "transaction")
+}
+
+func TestDecodeError_Error(t *testing.T) {
+	err := &codec.DecodeError{Violations: []codec.Violation{
+		{Path: "$.a", Message: "unknown field"},
+		{Path: "$.b", Message: "unknown field"},
+		{Path: "$.c", Message: "unknown field"},
+		{Path: "$.d", Message: "unknown field"},
+		{Path: "$.e", Message: "unknown field"},
+	}}
+	require.EqualError(t, err, "invalid payload: $.a: unknown field; $.b: unknown field; $.c: unknown field (+2 more)")
+	require.Equal(t, "$.e: unknown field", err.Strings()[4])
+}
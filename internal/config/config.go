@@ -4,31 +4,156 @@ import (
 	"errors"
 	"os"
 	"strconv"
+	"time"
+
+	"github.com/mrussa/L0/internal/codec"
 )
 
 type Config struct {
 	HTTPAddr       string
-	PostgresDSN    string
-	CacheWarmLimit int
+	MetricsEnabled bool
+	AdminToken     string
+
+	LogFormat string
+	LogLevel  string
+	LogLevels string
+	LogAccess bool
+
+	TracingExporter    string
+	TracingEndpoint    string
+	TracingServiceName string
+
+	PostgresDSN         string
+	CacheWarmLimit      int
+	CacheMaxEntries     int
+	CacheTTL            time.Duration
+	CacheNegativeTTL    time.Duration
+	CacheBackend        string
+	CacheSnapshotPath   string
+	CacheSnapshotMaxAge time.Duration
+
+	CacheWarmStrategy string
+	CacheWarmFile     string
+	CacheWarmWorkers  int
+	CacheWarmBudget   time.Duration
+	CacheWarmAsync    bool
+	CacheAccessLog    string
+
+	RedisAddr     string
+	RedisPassword string
+	RedisDB       int
+
+	KafkaBrokers  string
+	KafkaTopic    string
+	KafkaGroup    string
+	KafkaDLQTopic string
+
+	KafkaFormat       codec.Format
+	SchemaRegistryURL string
+	KafkaJSONStrict   bool
+	KafkaJSONSchema   string
+	ValidationRules   string
 
-	KafkaBrokers string
-	KafkaTopic   string
-	KafkaGroup   string
+	KafkaRetryAttempts  int
+	KafkaRetryBase      time.Duration
+	KafkaRetryMax       time.Duration
+	KafkaRetryExhausted string
+	KafkaWorkers        int
+	KafkaBatchSize      int
+	KafkaBatchWait      time.Duration
+
+	KafkaRestartBase time.Duration
+	KafkaRestartMax  time.Duration
+	KafkaMaxFailures int
 }
 
 func Load() (Config, error) {
 	var cfg Config
 
 	cfg.HTTPAddr = getEnv("HTTP_ADDR", ":8081")
+	cfg.MetricsEnabled = getEnvBool("METRICS_ENABLED", true)
+	cfg.AdminToken = getEnv("ADMIN_TOKEN", "")
+	cfg.LogFormat = getEnv("LOG_FORMAT", "text")
+	if cfg.LogFormat != "text" && cfg.LogFormat != "json" {
+		return Config{}, errors.New("LOG_FORMAT must be text or json")
+	}
+	cfg.LogLevel = getEnv("LOG_LEVEL", "info")
+	cfg.LogLevels = getEnv("LOG_LEVELS", "")
+	cfg.LogAccess = getEnvBool("LOG_ACCESS", true)
+	cfg.TracingExporter = getEnv("OTEL_TRACES_EXPORTER", "none")
+	if cfg.TracingExporter != "none" && cfg.TracingExporter != "otlp" && cfg.TracingExporter != "stdout" {
+		return Config{}, errors.New("OTEL_TRACES_EXPORTER must be none, otlp or stdout")
+	}
+	cfg.TracingEndpoint = getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://localhost:4318")
+	cfg.TracingServiceName = getEnv("OTEL_SERVICE_NAME", "l0-orders")
 	cfg.PostgresDSN = getEnv("POSTGRES_DSN", "")
 	if cfg.PostgresDSN == "" {
 		return Config{}, errors.New("set POSTGRES_DSN")
 	}
 
 	cfg.CacheWarmLimit = getEnvInt("CACHE_WARM_LIMIT", 100)
+	cfg.CacheWarmStrategy = getEnv("CACHE_WARM_STRATEGY", "recent")
+	cfg.CacheWarmFile = getEnv("CACHE_WARM_FILE", "")
+	cfg.CacheWarmWorkers = getEnvInt("CACHE_WARM_WORKERS", 4)
+	cfg.CacheWarmBudget = getEnvDuration("CACHE_WARM_BUDGET", 30*time.Second)
+	cfg.CacheWarmAsync = getEnvBool("CACHE_WARM_ASYNC", false)
+	cfg.CacheAccessLog = getEnv("CACHE_ACCESS_LOG", "")
+	switch cfg.CacheWarmStrategy {
+	case "recent":
+	case "frequent":
+		if cfg.CacheAccessLog == "" {
+			return Config{}, errors.New("CACHE_WARM_STRATEGY=frequent requires CACHE_ACCESS_LOG")
+		}
+	case "file":
+		if cfg.CacheWarmFile == "" {
+			return Config{}, errors.New("CACHE_WARM_STRATEGY=file requires CACHE_WARM_FILE")
+		}
+	default:
+		return Config{}, errors.New("CACHE_WARM_STRATEGY must be recent, frequent or file")
+	}
+	cfg.CacheMaxEntries = getEnvInt("CACHE_MAX_ENTRIES", 10000)
+	cfg.CacheTTL = getEnvDuration("CACHE_TTL", 0)
+	cfg.CacheNegativeTTL = getEnvDuration("CACHE_NEGATIVE_TTL", 5*time.Second)
+	cfg.CacheSnapshotPath = getEnv("CACHE_SNAPSHOT_PATH", "")
+	cfg.CacheSnapshotMaxAge = getEnvDuration("CACHE_SNAPSHOT_MAX_AGE", 10*time.Minute)
+	cfg.CacheBackend = getEnv("CACHE_BACKEND", "memory")
+	if cfg.CacheBackend != "memory" && cfg.CacheBackend != "redis" {
+		return Config{}, errors.New("CACHE_BACKEND must be memory or redis")
+	}
+	cfg.RedisAddr = getEnv("REDIS_ADDR", "localhost:6379")
+	cfg.RedisPassword = getEnv("REDIS_PASSWORD", "")
+	cfg.RedisDB = getEnvInt("REDIS_DB", 0)
+
 	cfg.KafkaBrokers = getEnv("KAFKA_BROKERS", "localhost:9092")
 	cfg.KafkaTopic = getEnv("KAFKA_TOPIC", "orders")
 	cfg.KafkaGroup = getEnv("KAFKA_GROUP", "orders-consumer")
+	cfg.KafkaDLQTopic = getEnv("KAFKA_DLQ_TOPIC", "")
+	format, err := codec.ParseFormat(getEnv("KAFKA_FORMAT", "json"))
+	if err != nil {
+		return Config{}, errors.New("KAFKA_FORMAT must be json, protobuf or avro")
+	}
+	cfg.KafkaFormat = format
+	cfg.SchemaRegistryURL = getEnv("SCHEMA_REGISTRY_URL", "")
+	cfg.KafkaJSONStrict = getEnvBool("KAFKA_JSON_STRICT", false)
+	cfg.KafkaJSONSchema = getEnv("KAFKA_JSON_SCHEMA", "")
+	cfg.ValidationRules = getEnv("VALIDATION_RULES", "required,amount")
+
+	cfg.KafkaRetryAttempts = getEnvInt("KAFKA_RETRY_ATTEMPTS", 5)
+	cfg.KafkaRetryBase = getEnvDuration("KAFKA_RETRY_BASE", 300*time.Millisecond)
+	cfg.KafkaRetryMax = getEnvDuration("KAFKA_RETRY_MAX", 10*time.Second)
+	if cfg.KafkaRetryMax == 0 {
+		cfg.KafkaRetryMax = 10 * time.Second
+	}
+	cfg.KafkaRetryExhausted = getEnv("KAFKA_RETRY_EXHAUSTED", "halt")
+	if cfg.KafkaRetryExhausted != "halt" && cfg.KafkaRetryExhausted != "dlq" {
+		return Config{}, errors.New("KAFKA_RETRY_EXHAUSTED must be halt or dlq")
+	}
+	cfg.KafkaWorkers = getEnvInt("KAFKA_WORKERS", 4)
+	cfg.KafkaBatchSize = getEnvInt("KAFKA_BATCH_SIZE", 100)
+	cfg.KafkaBatchWait = getEnvDuration("KAFKA_BATCH_WAIT", 50*time.Millisecond)
+	cfg.KafkaRestartBase = getEnvDuration("KAFKA_RESTART_BASE", time.Second)
+	cfg.KafkaRestartMax = getEnvDuration("KAFKA_RESTART_MAX", time.Minute)
+	cfg.KafkaMaxFailures = getEnvInt("KAFKA_MAX_FAILURES", 0)
 
 	return cfg, nil
 }
@@ -48,3 +173,21 @@ func getEnvInt(key string, def int) int {
 	}
 	return def
 }
+
+func getEnvBool(key string, def bool) bool {
+	if v := os.Getenv(key); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	}
+	return def
+}
+
+func getEnvDuration(key string, def time.Duration) time.Duration {
+	if v := os.Getenv(key); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			return d
+		}
+	}
+	return def
+}
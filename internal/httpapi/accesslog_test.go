@@ -0,0 +1,77 @@
+package httpapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/mrussa/L0/internal/cache"
+	"github.com/mrussa/L0/internal/logging"
+	"github.com/mrussa/L0/internal/repo"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeLines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var out []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var m map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &m), line)
+		out = append(out, m)
+	}
+	return out
+}
+
+func TestAccessLog_RequestIDAndOrderUID(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	logs, err := logging.New(&buf, logging.Config{Format: logging.FormatJSON, Level: "debug"})
+	require.NoError(t, err)
+
+	c := cache.New()
+	c.Set("u1", repo.Order{OrderUID: "u1"})
+	h := New(fakeRepo{}, c, logs.Component("http"), "testver", WithAccessLog(logs.Component("access"))).Routes()
+
+	rr, _ := doJSON(t, h, http.MethodGet, "/order/u1", nil, map[string]string{headerRequestID: "req-42"})
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	lines := decodeLines(t, &buf)
+	require.Len(t, lines, 2)
+
+	hit := lines[0]
+	require.Equal(t, "cache hit", hit["msg"])
+	require.Equal(t, "DEBUG", hit["level"])
+	require.Equal(t, "http", hit["component"])
+	require.Equal(t, "u1", hit["order_uid"])
+	require.Equal(t, "req-42", hit["request_id"])
+
+	access := lines[1]
+	require.Equal(t, "http request", access["msg"])
+	require.Equal(t, "access", access["component"])
+	require.Equal(t, "req-42", access["request_id"])
+	require.Equal(t, "/order/u1", access["path"])
+	require.Equal(t, "/order/", access["route"])
+	require.EqualValues(t, http.StatusOK, access["status"])
+	require.Greater(t, access["bytes"], float64(0))
+}
+
+func TestAccessLog_ErrorLevels(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	logs, err := logging.New(&buf, logging.Config{Format: logging.FormatJSON, Levels: "access=warn"})
+	require.NoError(t, err)
+	h := New(fakeRepo{Err: repo.ErrNotFound}, cache.New(), logs.Component("http"), "testver", WithAccessLog(logs.Component("access"))).Routes()
+
+	rr, _ := doJSON(t, h, http.MethodGet, "/healthz", nil, nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Empty(t, buf.String(), "успешные запросы ниже порога access=warn")
+
+	rr, _ = doJSON(t, h, http.MethodGet, "/order/missing", nil, nil)
+	require.Equal(t, http.StatusNotFound, rr.Code)
+	lines := decodeLines(t, &buf)
+	require.Len(t, lines, 1)
+	require.Equal(t, "WARN", lines[0]["level"])
+	require.EqualValues(t, http.StatusNotFound, lines[0]["status"])
+}
@@ -0,0 +1,133 @@
+package httpapi
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mrussa/L0/internal/cache"
+	"github.com/mrussa/L0/internal/respond"
+)
+
+type WarmStatus struct {
+	Running    bool      `json:"running"`
+	Warmed     int       `json:"warmed"`
+	Failed     int       `json:"failed"`
+	Error      string    `json:"error,omitempty"`
+	StartedAt  time.Time `json:"started_at,omitzero"`
+	FinishedAt time.Time `json:"finished_at,omitzero"`
+}
+
+type cacheStats struct {
+	cache.Stats
+	Warmup *WarmStatus `json:"warmup,omitempty"`
+}
+
+func WithAdminToken(token string) Option {
+	return func(a *OrdersAPI) { a.adminToken = token }
+}
+
+func (a *OrdersAPI) requireAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(a.adminToken)) != 1 {
+			a.log.WarnContext(r.Context(), "admin request rejected", "path", r.URL.Path, "remote", r.RemoteAddr)
+			w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+			respond.ErrorWithID(w, http.StatusUnauthorized, "unauthorized", "admin token required", RequestID(r))
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (a *OrdersAPI) handleCache(w http.ResponseWriter, r *http.Request) {
+	reqID := RequestID(r)
+	switch r.Method {
+	case http.MethodGet:
+		st := cacheStats{Stats: a.cache.Stats()}
+		if a.warm != nil {
+			ws := a.WarmStatus()
+			st.Warmup = &ws
+		}
+		respond.JSON(w, http.StatusOK, st)
+	case http.MethodDelete:
+		n := a.cache.Purge()
+		a.notFound.purge()
+		a.log.InfoContext(r.Context(), "cache purged", "entries", n)
+		respond.JSON(w, http.StatusOK, map[string]any{"purged": n})
+	default:
+		w.Header().Set("Allow", http.MethodGet+", "+http.MethodDelete)
+		respond.ErrorWithID(w, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed", reqID)
+	}
+}
+
+func (a *OrdersAPI) handleCacheKey(w http.ResponseWriter, r *http.Request) {
+	reqID := RequestID(r)
+	key := strings.TrimPrefix(r.URL.Path, "/admin/cache/")
+
+	if key == "warm" {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			respond.ErrorWithID(w, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed", reqID)
+			return
+		}
+		a.handleWarm(w, r, reqID)
+		return
+	}
+
+	if r.Method != http.MethodDelete {
+		w.Header().Set("Allow", http.MethodDelete)
+		respond.ErrorWithID(w, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed", reqID)
+		return
+	}
+	if key == "" || len(key) > 100 || strings.Contains(key, "/") {
+		respond.ErrorWithID(w, http.StatusBadRequest, "bad_request", "bad order_uid", reqID)
+		return
+	}
+	a.cache.Delete(key)
+	a.notFound.forget(key)
+	a.log.InfoContext(r.Context(), "cache entry invalidated", "order_uid", key)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *OrdersAPI) handleWarm(w http.ResponseWriter, r *http.Request, reqID string) {
+	if a.warm == nil {
+		respond.ErrorWithID(w, http.StatusNotImplemented, "not_implemented", "warm-up is not configured", reqID)
+		return
+	}
+	a.warmMu.Lock()
+	if a.warmStatus.Running {
+		a.warmMu.Unlock()
+		respond.ErrorWithID(w, http.StatusConflict, "conflict", "warm-up already running", reqID)
+		return
+	}
+	a.warmStatus = WarmStatus{Running: true, StartedAt: time.Now()}
+	st := a.warmStatus
+	a.warmMu.Unlock()
+
+	go a.runWarm(context.WithoutCancel(r.Context()))
+	respond.JSON(w, http.StatusAccepted, st)
+}
+
+func (a *OrdersAPI) runWarm(ctx context.Context) {
+	ok, failed, err := a.warm(ctx)
+	st := WarmStatus{Warmed: ok, Failed: failed, FinishedAt: time.Now()}
+	if err != nil {
+		a.log.ErrorContext(ctx, "warm-up failed", "err", err)
+		st.Error = err.Error()
+	} else {
+		a.log.InfoContext(ctx, "warm-up finished", "warmed", ok, "failed", failed)
+	}
+	a.warmMu.Lock()
+	st.StartedAt = a.warmStatus.StartedAt
+	a.warmStatus = st
+	a.warmMu.Unlock()
+}
+
+func (a *OrdersAPI) WarmStatus() WarmStatus {
+	a.warmMu.Lock()
+	defer a.warmMu.Unlock()
+	return a.warmStatus
+}
@@ -0,0 +1,139 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/mrussa/L0/internal/cache"
+	"github.com/mrussa/L0/internal/repo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminCache_StatsPurgeInvalidate(t *testing.T) {
+	t.Parallel()
+	c := cache.New()
+	c.Set("u1", repo.Order{OrderUID: "u1"})
+	c.Set("u2", repo.Order{OrderUID: "u2"})
+	c.Get("u1")
+	c.Get("nope")
+	h := newAPI(fakeRepo{}, c).Routes()
+
+	rr, m := doJSON(t, h, http.MethodGet, "/admin/cache", nil, adminHdr)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.EqualValues(t, 2, m["size"])
+	require.EqualValues(t, 1, m["hits"])
+	require.EqualValues(t, 1, m["misses"])
+	require.EqualValues(t, 0.5, m["hit_ratio"])
+
+	rr, _ = doJSON(t, h, http.MethodDelete, "/admin/cache/u1", nil, adminHdr)
+	require.Equal(t, http.StatusNoContent, rr.Code)
+	require.Equal(t, 1, c.Len())
+
+	rr, m = doJSON(t, h, http.MethodDelete, "/admin/cache", nil, adminHdr)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.EqualValues(t, 1, m["purged"])
+	require.Equal(t, 0, c.Len())
+
+	rr, _ = doJSON(t, h, http.MethodPost, "/admin/cache", nil, adminHdr)
+	require.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+	require.Equal(t, "GET, DELETE", rr.Header().Get("Allow"))
+
+	rr, _ = doJSON(t, h, http.MethodGet, "/admin/cache/u1", nil, adminHdr)
+	require.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+
+	rr, _ = doJSON(t, h, http.MethodDelete, "/admin/cache/a/b", nil, adminHdr)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestAdminCache_Warm(t *testing.T) {
+	t.Parallel()
+	c := cache.New()
+	h := newAPI(fakeRepo{}, c).Routes()
+	rr, m := doJSON(t, h, http.MethodPost, "/admin/cache/warm", nil, adminHdr)
+	require.Equal(t, http.StatusNotImplemented, rr.Code)
+	require.Equal(t, "not_implemented", m["error"])
+
+	release := make(chan struct{})
+	warm := func(ctx context.Context) (int, int, error) {
+		<-release
+		c.Set("w1", repo.Order{OrderUID: "w1"})
+		return 1, 2, nil
+	}
+	h = New(fakeRepo{}, c, discard, "testver", WithWarmer(warm), WithAdminToken(testAdminToken)).Routes()
+	rr, m = doJSON(t, h, http.MethodPost, "/admin/cache/warm", nil, adminHdr)
+	require.Equal(t, http.StatusAccepted, rr.Code, "прогрев идёт в фоне")
+	require.Equal(t, true, m["running"])
+
+	rr, m = doJSON(t, h, http.MethodPost, "/admin/cache/warm", nil, adminHdr)
+	require.Equal(t, http.StatusConflict, rr.Code, "второй прогрев параллельно не запускаем")
+	require.Equal(t, "conflict", m["error"])
+
+	close(release)
+	var st map[string]any
+	require.Eventually(t, func() bool {
+		_, m := doJSON(t, h, http.MethodGet, "/admin/cache", nil, adminHdr)
+		st, _ = m["warmup"].(map[string]any)
+		return st != nil && st["running"] == false
+	}, time.Second, time.Millisecond)
+	require.EqualValues(t, 1, st["warmed"])
+	require.EqualValues(t, 2, st["failed"])
+	require.NotEmpty(t, st["finished_at"])
+	require.Equal(t, 1, c.Len())
+
+	rr, _ = doJSON(t, h, http.MethodGet, "/admin/cache/warm", nil, adminHdr)
+	require.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+	require.Equal(t, http.MethodPost, rr.Header().Get("Allow"))
+
+	failing := func(ctx context.Context) (int, int, error) { return 0, 0, errors.New("db down") }
+	h = New(fakeRepo{}, c, discard, "testver", WithWarmer(failing), WithAdminToken(testAdminToken)).Routes()
+	rr, _ = doJSON(t, h, http.MethodPost, "/admin/cache/warm", nil, adminHdr)
+	require.Equal(t, http.StatusAccepted, rr.Code)
+	require.Eventually(t, func() bool {
+		_, m := doJSON(t, h, http.MethodGet, "/admin/cache", nil, adminHdr)
+		st, _ = m["warmup"].(map[string]any)
+		return st != nil && st["running"] == false
+	}, time.Second, time.Millisecond)
+	require.Equal(t, "db down", st["error"])
+}
+
+func TestAdminCache_RequiresToken(t *testing.T) {
+	t.Parallel()
+	c := cache.New()
+	c.Set("u1", repo.Order{OrderUID: "u1"})
+	h := newAPI(fakeRepo{}, c).Routes()
+
+	for _, hdr := range []map[string]string{
+		nil,
+		{"Authorization": "Bearer wrong"},
+		{"Authorization": testAdminToken},
+	} {
+		rr, m := doJSON(t, h, http.MethodDelete, "/admin/cache", nil, hdr)
+		require.Equal(t, http.StatusUnauthorized, rr.Code)
+		require.Equal(t, "unauthorized", m["error"])
+		require.Equal(t, `Bearer realm="admin"`, rr.Header().Get("WWW-Authenticate"))
+	}
+	rr, _ := doJSON(t, h, http.MethodPost, "/admin/cache/warm", nil, nil)
+	require.Equal(t, http.StatusUnauthorized, rr.Code)
+	require.Equal(t, 1, c.Len(), "без токена кэш не трогаем")
+}
+
+func TestAdminCache_DisabledWithoutToken(t *testing.T) {
+	t.Parallel()
+	c := cache.New()
+	c.Set("u1", repo.Order{OrderUID: "u1"})
+	h := New(fakeRepo{}, c, discard, "testver").Routes()
+
+	for _, tc := range []struct{ method, path string }{
+		{http.MethodGet, "/admin/cache"},
+		{http.MethodDelete, "/admin/cache"},
+		{http.MethodDelete, "/admin/cache/u1"},
+		{http.MethodPost, "/admin/cache/warm"},
+	} {
+		rr, _ := doJSON(t, h, tc.method, tc.path, nil, adminHdr)
+		require.Equal(t, http.StatusNotFound, rr.Code, tc.path)
+	}
+	require.Equal(t, 1, c.Len())
+}
@@ -0,0 +1,113 @@
+package httpapi
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mrussa/L0/internal/cache"
+	"github.com/mrussa/L0/internal/repo"
+	"github.com/stretchr/testify/require"
+)
+
+type slowRepo struct {
+	fakeRepo
+	calls atomic.Int32
+	gate  chan struct{}
+}
+
+func (s *slowRepo) GetOrder(ctx context.Context, id string) (repo.Order, error) {
+	s.calls.Add(1)
+	<-s.gate
+	return s.Order, s.Err
+}
+
+func TestOrder_ConcurrentMisses_SingleLoad(t *testing.T) {
+	t.Parallel()
+	src := &slowRepo{fakeRepo: fakeRepo{Order: repo.Order{OrderUID: "hot"}}, gate: make(chan struct{})}
+	c := cache.New()
+	h := newAPI(src, c).Routes()
+
+	const n = 20
+	var wg sync.WaitGroup
+	codes := make([]int, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rr, _ := doJSON(t, h, http.MethodGet, "/order/hot", nil, nil)
+			codes[i] = rr.Code
+		}()
+	}
+
+	require.Eventually(t, func() bool { return src.calls.Load() == 1 }, time.Second, time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	close(src.gate)
+	wg.Wait()
+
+	require.Equal(t, int32(1), src.calls.Load(), "параллельные промахи должны схлопнуться в одну загрузку")
+	for _, code := range codes {
+		require.Equal(t, http.StatusOK, code)
+	}
+	_, ok := c.Get("hot")
+	require.True(t, ok)
+}
+
+func TestOrder_NegativeCache(t *testing.T) {
+	t.Parallel()
+	src := &slowRepo{fakeRepo: fakeRepo{Err: repo.ErrNotFound}, gate: make(chan struct{})}
+	close(src.gate)
+	c := cache.New()
+	api := New(src, c, discard, "testver", WithNegativeTTL(time.Minute), WithAdminToken(testAdminToken))
+	h := api.Routes()
+
+	for i := 0; i < 3; i++ {
+		rr, m := doJSON(t, h, http.MethodGet, "/order/ghost", nil, nil)
+		require.Equal(t, http.StatusNotFound, rr.Code)
+		require.Equal(t, "order not found", m["message"])
+	}
+	require.Equal(t, int32(1), src.calls.Load(), "повторные запросы несуществующего uid не должны идти в БД")
+
+	c.Set("ghost", repo.Order{OrderUID: "ghost"})
+	rr, _ := doJSON(t, h, http.MethodGet, "/order/ghost", nil, nil)
+	require.Equal(t, http.StatusOK, rr.Code, "положительный кэш важнее отрицательного")
+
+	c.Delete("ghost")
+	rr, _ = doJSON(t, h, http.MethodDelete, "/admin/cache/ghost", nil, adminHdr)
+	require.Equal(t, http.StatusNoContent, rr.Code)
+	doJSON(t, h, http.MethodGet, "/order/ghost", nil, nil)
+	require.Equal(t, int32(2), src.calls.Load(), "инвалидация сбрасывает отрицательную запись")
+}
+
+func TestNegativeCache_Expiry(t *testing.T) {
+	t.Parallel()
+	now := time.Unix(0, 0)
+	n := newNegativeCache(time.Second)
+	n.now = func() time.Time { return now }
+
+	n.add("a")
+	require.True(t, n.has("a"))
+	now = now.Add(time.Second)
+	require.False(t, n.has("a"))
+
+	var off *negativeCache
+	off.add("a")
+	require.False(t, off.has("a"))
+	off.forget("a")
+	off.purge()
+}
+
+func TestOrder_ClientGone_DoesNotBlock(t *testing.T) {
+	t.Parallel()
+	src := &slowRepo{fakeRepo: fakeRepo{Order: repo.Order{OrderUID: "x"}}, gate: make(chan struct{})}
+	defer close(src.gate)
+	api := newAPI(src, cache.New())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := api.loadOrder(ctx, "x")
+	require.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,114 @@
+package httpapi
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/mrussa/L0/internal/respond"
+)
+
+const readyTimeout = 2 * time.Second
+
+type Check func(ctx context.Context) (detail map[string]any, err error)
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+type CheckResult struct {
+	Status     string         `json:"status"`
+	Error      string         `json:"error,omitempty"`
+	DurationMS int64          `json:"duration_ms"`
+	Detail     map[string]any `json:"detail,omitempty"`
+}
+
+func WithReadyCheck(name string, c Check) Option {
+	return func(a *OrdersAPI) { a.checks = append(a.checks, namedCheck{name, c}) }
+}
+
+func (a *OrdersAPI) handleLive(w http.ResponseWriter, r *http.Request) {
+	reqID := RequestID(r)
+	if !allowGetHead(w, r, reqID) {
+		return
+	}
+	if r.Method == http.MethodHead {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{
+		"status":     "ok",
+		"version":    a.version,
+		"request_id": reqID,
+	})
+}
+
+func (a *OrdersAPI) handleReady(w http.ResponseWriter, r *http.Request) {
+	reqID := RequestID(r)
+	if !allowGetHead(w, r, reqID) {
+		return
+	}
+
+	results, ok := a.runChecks(r.Context())
+	status, code := "ok", http.StatusOK
+	if !ok {
+		status, code = "degraded", http.StatusServiceUnavailable
+		for name, res := range results {
+			if res.Status != "ok" {
+				a.log.WarnContext(r.Context(), "readiness check failed", "check", name, "err", res.Error)
+			}
+		}
+	}
+	if r.Method == http.MethodHead {
+		w.WriteHeader(code)
+		return
+	}
+	respond.JSON(w, code, map[string]any{
+		"status":     status,
+		"checks":     results,
+		"version":    a.version,
+		"request_id": reqID,
+	})
+}
+
+func (a *OrdersAPI) runChecks(ctx context.Context) (map[string]CheckResult, bool) {
+	ctx, cancel := context.WithTimeout(ctx, readyTimeout)
+	defer cancel()
+
+	results := make([]CheckResult, len(a.checks))
+	var wg sync.WaitGroup
+	for i, c := range a.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			detail, err := c.check(ctx)
+			res := CheckResult{Status: "ok", Detail: detail, DurationMS: time.Since(start).Milliseconds()}
+			if err != nil {
+				res.Status = "fail"
+				res.Error = err.Error()
+			}
+			results[i] = res
+		}()
+	}
+	wg.Wait()
+
+	out := make(map[string]CheckResult, len(results))
+	ok := true
+	for i, c := range a.checks {
+		out[c.name] = results[i]
+		ok = ok && results[i].Status == "ok"
+	}
+	return out, ok
+}
+
+func allowGetHead(w http.ResponseWriter, r *http.Request, reqID string) bool {
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		return true
+	}
+	w.Header().Set("Allow", http.MethodGet+", "+http.MethodHead)
+	respond.ErrorWithID(w, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed", reqID)
+	return false
+}
@@ -0,0 +1,111 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/mrussa/L0/internal/cache"
+	"github.com/stretchr/testify/require"
+)
+
+func okCheck(detail map[string]any) Check {
+	return func(context.Context) (map[string]any, error) { return detail, nil }
+}
+
+func TestLivez(t *testing.T) {
+	t.Parallel()
+	api := New(fakeRepo{}, cache.New(), discard, "testver",
+		WithReadyCheck("postgres", func(context.Context) (map[string]any, error) { return nil, errors.New("down") }))
+	h := api.Routes()
+
+	rr, m := doJSON(t, h, http.MethodGet, "/livez", nil, map[string]string{headerRequestID: "rid-1"})
+	require.Equal(t, http.StatusOK, rr.Code, "liveness не зависит от внешних сервисов")
+	require.Equal(t, "ok", m["status"])
+	require.Equal(t, "testver", m["version"])
+	require.Equal(t, "rid-1", m["request_id"])
+
+	rr, _ = doJSON(t, h, http.MethodHead, "/livez", nil, nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	rr, m = doJSON(t, h, http.MethodPost, "/livez", nil, nil)
+	require.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+	require.Equal(t, "GET, HEAD", rr.Header().Get("Allow"))
+	require.Equal(t, "method_not_allowed", m["error"])
+}
+
+func TestReadyz_AllOK(t *testing.T) {
+	t.Parallel()
+	api := New(fakeRepo{}, cache.New(), discard, "testver",
+		WithReadyCheck("postgres", okCheck(nil)),
+		WithReadyCheck("kafka", okCheck(map[string]any{"running": true})))
+	h := api.Routes()
+
+	rr, m := doJSON(t, h, http.MethodGet, "/readyz", nil, nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, "ok", m["status"])
+
+	checks := m["checks"].(map[string]any)
+	require.Len(t, checks, 2)
+	pg := checks["postgres"].(map[string]any)
+	require.Equal(t, "ok", pg["status"])
+	require.NotContains(t, pg, "error")
+	kc := checks["kafka"].(map[string]any)
+	require.Equal(t, map[string]any{"running": true}, kc["detail"])
+
+	rr, _ = doJSON(t, h, http.MethodHead, "/readyz", nil, nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestReadyz_Degraded(t *testing.T) {
+	t.Parallel()
+	api := New(fakeRepo{}, cache.New(), discard, "testver",
+		WithReadyCheck("postgres", okCheck(nil)),
+		WithReadyCheck("warmup", func(context.Context) (map[string]any, error) {
+			return map[string]any{"done": false}, errors.New("warm-up in progress")
+		}))
+	h := api.Routes()
+
+	rr, m := doJSON(t, h, http.MethodGet, "/readyz", nil, nil)
+	require.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	require.Equal(t, "degraded", m["status"])
+
+	checks := m["checks"].(map[string]any)
+	require.Equal(t, "ok", checks["postgres"].(map[string]any)["status"])
+	w := checks["warmup"].(map[string]any)
+	require.Equal(t, "fail", w["status"])
+	require.Equal(t, "warm-up in progress", w["error"])
+	require.Equal(t, map[string]any{"done": false}, w["detail"])
+
+	rr, _ = doJSON(t, h, http.MethodHead, "/readyz", nil, nil)
+	require.Equal(t, http.StatusServiceUnavailable, rr.Code)
+}
+
+func TestReadyz_CheckTimeout(t *testing.T) {
+	t.Parallel()
+	api := New(fakeRepo{}, cache.New(), discard, "testver",
+		WithReadyCheck("postgres", func(ctx context.Context) (map[string]any, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}))
+
+	res, ok := api.runChecks(canceledCtx())
+	require.False(t, ok)
+	require.Equal(t, "fail", res["postgres"].Status)
+	require.Equal(t, context.Canceled.Error(), res["postgres"].Error)
+}
+
+func TestReadyz_NoChecks(t *testing.T) {
+	t.Parallel()
+	rr, m := doJSON(t, newAPI(fakeRepo{}, cache.New()).Routes(), http.MethodGet, "/readyz", nil, nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, "ok", m["status"])
+	require.Empty(t, m["checks"])
+}
+
+func canceledCtx() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	return ctx
+}
@@ -0,0 +1,57 @@
+package httpapi
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/mrussa/L0/internal/repo"
+)
+
+const maxListLimit = 100
+
+func parseOrderFilter(q url.Values) (repo.OrderFilter, error) {
+	f := repo.OrderFilter{
+		CustomerID:      q.Get("customer_id"),
+		TrackNumber:     q.Get("track_number"),
+		DeliveryService: q.Get("delivery_service"),
+		Currency:        q.Get("currency"),
+		Provider:        q.Get("provider"),
+		Cursor:          q.Get("cursor"),
+	}
+
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxListLimit {
+			return f, fmt.Errorf("limit must be 1..%d", maxListLimit)
+		}
+		f.Limit = n
+	}
+
+	var err error
+	if f.CreatedFrom, err = parseTimeParam(q.Get("from")); err != nil {
+		return f, fmt.Errorf("bad from: %w", err)
+	}
+	if f.CreatedTo, err = parseTimeParam(q.Get("to")); err != nil {
+		return f, fmt.Errorf("bad to: %w", err)
+	}
+	if !f.CreatedFrom.IsZero() && !f.CreatedTo.IsZero() && !f.CreatedFrom.Before(f.CreatedTo) {
+		return f, fmt.Errorf("from must be before to")
+	}
+	return f, nil
+}
+
+func parseTimeParam(v string) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.DateOnly, v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("want RFC3339 or YYYY-MM-DD")
+	}
+	return t, nil
+}
@@ -0,0 +1,79 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/mrussa/L0/internal/cache"
+	"github.com/mrussa/L0/internal/repo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrders_List_PassesFilterAndReturnsPage(t *testing.T) {
+	t.Parallel()
+	var got repo.OrderFilter
+	page := repo.OrdersPage{
+		Orders:     []repo.OrderSummary{{OrderUID: "u1", Currency: "USD"}},
+		NextCursor: "next",
+	}
+	api := newAPI(fakeRepo{Page: page, Filter: &got}, cache.New())
+	h := api.Routes()
+
+	rr, m := doJSON(t, h, http.MethodGet,
+		"/orders?customer_id=c1&track_number=TN&delivery_service=meest&currency=USD&provider=wbpay&from=2024-03-01&to=2024-03-02T12:00:00Z&limit=5&cursor=abc",
+		nil, nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, "next", m["next_cursor"])
+	require.Len(t, m["orders"], 1)
+
+	require.Equal(t, repo.OrderFilter{
+		CustomerID:      "c1",
+		TrackNumber:     "TN",
+		DeliveryService: "meest",
+		Currency:        "USD",
+		Provider:        "wbpay",
+		CreatedFrom:     time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		CreatedTo:       time.Date(2024, 3, 2, 12, 0, 0, 0, time.UTC),
+		Cursor:          "abc",
+		Limit:           5,
+	}, got)
+}
+
+func TestOrders_List_BadParams(t *testing.T) {
+	t.Parallel()
+	api := newAPI(fakeRepo{}, cache.New())
+	h := api.Routes()
+
+	for _, q := range []string{
+		"limit=0",
+		"limit=101",
+		"limit=x",
+		"from=yesterday",
+		"to=2024-13-01",
+		"from=2024-03-02&to=2024-03-01",
+	} {
+		rr, m := doJSON(t, h, http.MethodGet, "/orders?"+q, nil, nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code, q)
+		require.Equal(t, "bad_request", m["error"], q)
+	}
+
+	rr, m := doJSON(t, h, http.MethodPost, "/orders", nil, nil)
+	require.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+	require.Equal(t, http.MethodGet, rr.Header().Get("Allow"))
+	require.Equal(t, "method_not_allowed", m["error"])
+}
+
+func TestOrders_List_RepoErrors(t *testing.T) {
+	t.Parallel()
+	h := newAPI(fakeRepo{Err: repo.ErrBadCursor}, cache.New()).Routes()
+	rr, m := doJSON(t, h, http.MethodGet, "/orders?cursor=zzz", nil, nil)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	require.Equal(t, "bad cursor", m["message"])
+
+	h = newAPI(fakeRepo{Err: errors.New("boom")}, cache.New()).Routes()
+	rr, m = doJSON(t, h, http.MethodGet, "/orders", nil, nil)
+	require.Equal(t, http.StatusInternalServerError, rr.Code)
+	require.Equal(t, "internal", m["error"])
+}
@@ -0,0 +1,68 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/mrussa/L0/internal/repo"
+	"github.com/mrussa/L0/internal/respond"
+)
+
+var lookupRoutes = map[string]repo.LookupKey{
+	"by-track":       repo.ByTrackNumber,
+	"by-transaction": repo.ByTransaction,
+	"by-rid":         repo.ByRID,
+	"by-chrt":        repo.ByChrtID,
+	"by-customer":    repo.ByCustomer,
+}
+
+func (a *OrdersAPI) handleLookup(w http.ResponseWriter, r *http.Request) {
+	reqID := RequestID(r)
+
+	kind, value, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/orders/"), "/")
+	key, ok := lookupRoutes[kind]
+	if !ok {
+		respond.ErrorWithID(w, http.StatusNotFound, "not_found", "not found", reqID)
+		return
+	}
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		respond.ErrorWithID(w, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed", reqID)
+		return
+	}
+
+	limit := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxListLimit {
+			respond.ErrorWithID(w, http.StatusBadRequest, "bad_request", "bad limit", reqID)
+			return
+		}
+		limit = n
+	}
+
+	uids, err := a.repo.FindOrderUIDs(r.Context(), key, value, limit)
+	if err != nil {
+		if errors.Is(err, repo.ErrBadLookup) {
+			respond.ErrorWithID(w, http.StatusBadRequest, "bad_request", "bad "+string(key), reqID)
+			return
+		}
+		a.log.ErrorContext(r.Context(), "lookup failed", "key", key, "value", value, "err", err)
+		respond.ErrorWithID(w, http.StatusInternalServerError, "internal", "internal error", reqID)
+		return
+	}
+
+	orders, err := a.loadOrders(r.Context(), uids)
+	if err != nil {
+		a.log.ErrorContext(r.Context(), "orders load failed", "orders", len(uids), "err", err)
+		respond.ErrorWithID(w, http.StatusInternalServerError, "internal", "internal error", reqID)
+		return
+	}
+	if len(orders) == 0 {
+		respond.ErrorWithID(w, http.StatusNotFound, "not_found", "order not found", reqID)
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"orders": orders})
+}
@@ -0,0 +1,100 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/mrussa/L0/internal/cache"
+	"github.com/mrussa/L0/internal/repo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookup_Routes_MapToKeys(t *testing.T) {
+	t.Parallel()
+	for path, want := range map[string]repo.LookupKey{
+		"/orders/by-track/TN1":        repo.ByTrackNumber,
+		"/orders/by-transaction/tx1":  repo.ByTransaction,
+		"/orders/by-rid/rid1":         repo.ByRID,
+		"/orders/by-chrt/9934930":     repo.ByChrtID,
+		"/orders/by-customer/test":    repo.ByCustomer,
+		"/orders/by-customer/test?x=": repo.ByCustomer,
+	} {
+		var got repo.LookupKey
+		h := newAPI(fakeRepo{UIDs: []string{"u1"}, Order: repo.Order{OrderUID: "u1"}, Lookup: &got}, cache.New()).Routes()
+		rr, _ := doJSON(t, h, http.MethodGet, path, nil, nil)
+		require.Equal(t, http.StatusOK, rr.Code, path)
+		require.Equal(t, want, got, path)
+	}
+}
+
+func TestLookup_UsesCacheAndFillsIt(t *testing.T) {
+	t.Parallel()
+	c := cache.New()
+	c.Set("u1", repo.Order{OrderUID: "u1", CustomerID: "cached"})
+	api := newAPI(fakeRepo{UIDs: []string{"u1", "u2"}, Order: repo.Order{OrderUID: "u2"}}, c)
+	h := api.Routes()
+
+	rr, _ := doJSON(t, h, http.MethodGet, "/orders/by-customer/test", nil, nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var body struct {
+		Orders []repo.Order `json:"orders"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	require.Len(t, body.Orders, 2)
+	require.Equal(t, "cached", body.Orders[0].CustomerID)
+	require.Equal(t, "u2", body.Orders[1].OrderUID)
+
+	_, ok := c.Get("u2")
+	require.True(t, ok, "заказ из БД должен попасть в кэш")
+}
+
+func TestLookup_LoadsMissesInOneQuery(t *testing.T) {
+	t.Parallel()
+	c := cache.New()
+	c.Set("u1", repo.Order{OrderUID: "u1"})
+	var loads [][]string
+	h := newAPI(fakeRepo{UIDs: []string{"u1", "u2", "u3", "gone"}, Order: repo.Order{OrderUID: "u3"}, Loads: &loads}, c).Routes()
+
+	rr, _ := doJSON(t, h, http.MethodGet, "/orders/by-customer/test", nil, nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var body struct {
+		Orders []repo.Order `json:"orders"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	require.Len(t, body.Orders, 2)
+	require.Equal(t, "u1", body.Orders[0].OrderUID)
+	require.Equal(t, "u3", body.Orders[1].OrderUID)
+	require.Equal(t, [][]string{{"u2", "u3", "gone"}}, loads, "промахи кэша — одним запросом")
+}
+
+func TestLookup_Errors(t *testing.T) {
+	t.Parallel()
+	h := newAPI(fakeRepo{}, cache.New()).Routes()
+
+	rr, m := doJSON(t, h, http.MethodGet, "/orders/by-nothing/x", nil, nil)
+	require.Equal(t, http.StatusNotFound, rr.Code)
+	require.Equal(t, "not_found", m["error"])
+
+	rr, m = doJSON(t, h, http.MethodGet, "/orders/by-track/", nil, nil)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	require.Equal(t, "bad track_number", m["message"])
+
+	rr, m = doJSON(t, h, http.MethodGet, "/orders/by-track/TN?limit=0", nil, nil)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	require.Equal(t, "bad limit", m["message"])
+
+	rr, m = doJSON(t, h, http.MethodGet, "/orders/by-track/TN", nil, nil)
+	require.Equal(t, http.StatusNotFound, rr.Code)
+	require.Equal(t, "order not found", m["message"])
+
+	rr, _ = doJSON(t, h, http.MethodPost, "/orders/by-track/TN", nil, nil)
+	require.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+	require.Equal(t, http.MethodGet, rr.Header().Get("Allow"))
+
+	h = newAPI(fakeRepo{UIDs: []string{"gone", "u1"}, Err: repo.ErrNotFound}, cache.New()).Routes()
+	rr, _ = doJSON(t, h, http.MethodGet, "/orders/by-rid/r1", nil, nil)
+	require.Equal(t, http.StatusNotFound, rr.Code)
+}
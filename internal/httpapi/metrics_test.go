@@ -0,0 +1,67 @@
+package httpapi
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mrussa/L0/internal/cache"
+	"github.com/mrussa/L0/internal/repo"
+	"github.com/stretchr/testify/require"
+)
+
+type observation struct {
+	route, method string
+	code          int
+}
+
+type fakeMetrics struct {
+	mu  sync.Mutex
+	obs []observation
+}
+
+func (m *fakeMetrics) ObserveHTTP(route, method string, code int, _ time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.obs = append(m.obs, observation{route, method, code})
+}
+
+func (m *fakeMetrics) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("l0_up 1\n"))
+	})
+}
+
+func TestMetrics_RouteLabelsAndEndpoint(t *testing.T) {
+	t.Parallel()
+	c := cache.New()
+	c.Set("u1", repo.Order{OrderUID: "u1"})
+	m := &fakeMetrics{}
+	api := New(fakeRepo{Err: repo.ErrNotFound}, c, discard, "testver", WithMetrics(m))
+	h := api.Routes()
+
+	rr, _ := doJSON(t, h, http.MethodGet, "/order/u1", nil, nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	rr, _ = doJSON(t, h, http.MethodGet, "/order/nope", nil, nil)
+	require.Equal(t, http.StatusNotFound, rr.Code)
+	rr, _ = doJSON(t, h, http.MethodPost, "/healthz", nil, nil)
+	require.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+	rr, _ = doJSON(t, h, http.MethodGet, "/metrics", nil, nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, "l0_up 1\n", rr.Body.String())
+
+	require.Equal(t, []observation{
+		{"/order/", http.MethodGet, http.StatusOK},
+		{"/order/", http.MethodGet, http.StatusNotFound},
+		{"/healthz", http.MethodPost, http.StatusMethodNotAllowed},
+		{"/metrics", http.MethodGet, http.StatusOK},
+	}, m.obs, "uid не должен попадать в метку route")
+}
+
+func TestMetrics_Disabled_NoEndpoint(t *testing.T) {
+	t.Parallel()
+	h := newAPI(fakeRepo{}, cache.New()).Routes()
+	rr, _ := doJSON(t, h, http.MethodGet, "/metrics", nil, nil)
+	require.Equal(t, http.StatusNotFound, rr.Code)
+}
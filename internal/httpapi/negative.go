@@ -0,0 +1,74 @@
+package httpapi
+
+import (
+	"sync"
+	"time"
+)
+
+const maxNegativeEntries = 10000
+
+type negativeCache struct {
+	mu  sync.Mutex
+	ttl time.Duration
+	m   map[string]time.Time
+	now func() time.Time
+}
+
+func newNegativeCache(ttl time.Duration) *negativeCache {
+	return &negativeCache{ttl: ttl, m: make(map[string]time.Time), now: time.Now}
+}
+
+func (n *negativeCache) has(uid string) bool {
+	if n == nil || n.ttl <= 0 {
+		return false
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	exp, ok := n.m[uid]
+	if !ok {
+		return false
+	}
+	if !n.now().Before(exp) {
+		delete(n.m, uid)
+		return false
+	}
+	return true
+}
+
+func (n *negativeCache) add(uid string) {
+	if n == nil || n.ttl <= 0 {
+		return
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	now := n.now()
+	if len(n.m) >= maxNegativeEntries {
+		for k, exp := range n.m {
+			if !now.Before(exp) {
+				delete(n.m, k)
+			}
+		}
+		if len(n.m) >= maxNegativeEntries {
+			clear(n.m)
+		}
+	}
+	n.m[uid] = now.Add(n.ttl)
+}
+
+func (n *negativeCache) forget(uid string) {
+	if n == nil {
+		return
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	delete(n.m, uid)
+}
+
+func (n *negativeCache) purge() {
+	if n == nil {
+		return
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	clear(n.m)
+}
@@ -3,32 +3,88 @@ package httpapi
 import (
 	"context"
 	"errors"
+	"log/slog"
 	"net/http"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/mrussa/L0/internal/cache"
 	"github.com/mrussa/L0/internal/repo"
 	"github.com/mrussa/L0/internal/respond"
+	"golang.org/x/sync/singleflight"
 )
 
 type OrderSource interface {
 	GetOrder(ctx context.Context, id string) (repo.Order, error)
+	GetOrders(ctx context.Context, uids []string) ([]repo.Order, error)
+	ListOrders(ctx context.Context, f repo.OrderFilter) (repo.OrdersPage, error)
+	FindOrderUIDs(ctx context.Context, key repo.LookupKey, value string, limit int) ([]string, error)
+	SearchOrders(ctx context.Context, q string, limit int) ([]repo.SearchHit, error)
+}
+
+type Warmer func(ctx context.Context) (ok, failed int, err error)
+
+type AccessRecorder interface {
+	Record(uid string)
 }
 
 type OrdersAPI struct {
-	repo    OrderSource
-	cache   *cache.OrdersCache
-	logf    func(string, ...any)
-	version string
+	repo      OrderSource
+	cache     cache.Cache
+	log       *slog.Logger
+	version   string
+	warm      Warmer
+	access    AccessRecorder
+	metrics   Metrics
+	accessLog *slog.Logger
+	checks    []namedCheck
+
+	adminToken string
+	warmMu     sync.Mutex
+	warmStatus WarmStatus
+
+	flight   singleflight.Group
+	notFound *negativeCache
+}
+
+type Option func(*OrdersAPI)
+
+func WithWarmer(w Warmer) Option {
+	return func(a *OrdersAPI) { a.warm = w }
+}
+
+func WithAccessRecorder(r AccessRecorder) Option {
+	return func(a *OrdersAPI) { a.access = r }
+}
+
+func WithMetrics(m Metrics) Option {
+	return func(a *OrdersAPI) { a.metrics = m }
+}
+
+func WithAccessLog(l *slog.Logger) Option {
+	return func(a *OrdersAPI) { a.accessLog = l }
+}
+
+func WithNegativeTTL(d time.Duration) Option {
+	return func(a *OrdersAPI) {
+		if d > 0 {
+			a.notFound = newNegativeCache(d)
+		}
+	}
 }
 
-func New(repo OrderSource, cache *cache.OrdersCache, logf func(string, ...any), version string) *OrdersAPI {
-	return &OrdersAPI{
+func New(repo OrderSource, cache cache.Cache, log *slog.Logger, version string, opts ...Option) *OrdersAPI {
+	a := &OrdersAPI{
 		repo:    repo,
 		cache:   cache,
-		logf:    logf,
+		log:     log,
 		version: version,
 	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
 }
 
 func (a *OrdersAPI) Routes() http.Handler {
@@ -61,11 +117,42 @@ func (a *OrdersAPI) Routes() http.Handler {
 		respond.JSON(w, http.StatusOK, map[string]any{
 			"status":     "ok",
 			"cache_size": a.cache.Len(),
+			"cache":      a.cache.Stats(),
 			"version":    a.version,
 			"request_id": reqID,
 		})
 	})
 
+	mux.HandleFunc("/livez", a.handleLive)
+	mux.HandleFunc("/readyz", a.handleReady)
+
+	mux.HandleFunc("/orders", func(w http.ResponseWriter, r *http.Request) {
+		reqID := RequestID(r)
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			respond.ErrorWithID(w, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed", reqID)
+			return
+		}
+
+		f, err := parseOrderFilter(r.URL.Query())
+		if err != nil {
+			respond.ErrorWithID(w, http.StatusBadRequest, "bad_request", err.Error(), reqID)
+			return
+		}
+
+		page, err := a.repo.ListOrders(r.Context(), f)
+		if err != nil {
+			if errors.Is(err, repo.ErrBadCursor) {
+				respond.ErrorWithID(w, http.StatusBadRequest, "bad_request", "bad cursor", reqID)
+				return
+			}
+			a.log.ErrorContext(r.Context(), "orders list failed", "err", err)
+			respond.ErrorWithID(w, http.StatusInternalServerError, "internal", "internal error", reqID)
+			return
+		}
+		respond.JSON(w, http.StatusOK, page)
+	})
+
 	mux.HandleFunc("/order", func(w http.ResponseWriter, r *http.Request) {
 		reqID := RequestID(r)
 		if r.Method != http.MethodGet {
@@ -93,14 +180,7 @@ func (a *OrdersAPI) Routes() http.Handler {
 			return
 		}
 
-		if o, ok := a.cache.Get(id); ok {
-			a.logf("cache hit id=%s", id)
-			respond.JSON(w, http.StatusOK, o)
-			return
-		}
-		a.logf("cache miss id=%s", id)
-
-		o, err := a.repo.GetOrder(r.Context(), id)
+		o, err := a.loadOrder(r.Context(), id)
 		if err != nil {
 			switch {
 			case errors.Is(err, repo.ErrBadUID):
@@ -108,15 +188,107 @@ func (a *OrdersAPI) Routes() http.Handler {
 			case errors.Is(err, repo.ErrNotFound):
 				respond.ErrorWithID(w, http.StatusNotFound, "not_found", "order not found", reqID)
 			default:
-				a.logf("order load failed id=%s err=%v", id, err)
+				a.log.ErrorContext(r.Context(), "order load failed", "order_uid", id, "err", err)
 				respond.ErrorWithID(w, http.StatusInternalServerError, "internal", "internal error", reqID)
 			}
 			return
 		}
-
-		a.cache.Set(id, o)
+		if a.access != nil {
+			a.access.Record(id)
+		}
 		respond.JSON(w, http.StatusOK, o)
 	})
 
-	return WithRequestID(mux)
+	if a.adminToken != "" {
+		mux.HandleFunc("/admin/cache", a.requireAdmin(a.handleCache))
+		mux.HandleFunc("/admin/cache/", a.requireAdmin(a.handleCacheKey))
+	}
+
+	mux.HandleFunc("/orders/search", a.handleSearch)
+	mux.HandleFunc("/orders/", a.handleLookup)
+
+	var h http.Handler = mux
+	if a.metrics != nil {
+		mux.Handle("/metrics", a.metrics.Handler())
+		h = instrument(mux, a.metrics)
+	}
+	if a.accessLog != nil {
+		h = accessLog(mux, a.accessLog, h)
+	}
+	return withTracing(mux, WithRequestID(h))
+}
+
+func (a *OrdersAPI) loadOrder(ctx context.Context, id string) (repo.Order, error) {
+	if o, ok := a.cache.Get(id); ok {
+		a.log.DebugContext(ctx, "cache hit", "order_uid", id)
+		return o, nil
+	}
+	if a.notFound.has(id) {
+		a.log.DebugContext(ctx, "cache negative hit", "order_uid", id)
+		return repo.Order{}, repo.ErrNotFound
+	}
+	a.log.DebugContext(ctx, "cache miss", "order_uid", id)
+
+	ch := a.flight.DoChan(id, func() (any, error) {
+		o, err := a.repo.GetOrder(context.WithoutCancel(ctx), id)
+		switch {
+		case err == nil:
+			a.cache.Set(id, o)
+		case errors.Is(err, repo.ErrNotFound):
+			a.notFound.add(id)
+		}
+		return o, err
+	})
+	select {
+	case res := <-ch:
+		if res.Shared {
+			a.log.DebugContext(ctx, "cache miss coalesced", "order_uid", id)
+		}
+		if res.Err != nil {
+			return repo.Order{}, res.Err
+		}
+		return res.Val.(repo.Order), nil
+	case <-ctx.Done():
+		return repo.Order{}, ctx.Err()
+	}
+}
+
+func (a *OrdersAPI) loadOrders(ctx context.Context, uids []string) ([]repo.Order, error) {
+	found := make(map[string]repo.Order, len(uids))
+	var misses []string
+	for _, uid := range uids {
+		if o, ok := a.cache.Get(uid); ok {
+			found[uid] = o
+			continue
+		}
+		if a.notFound.has(uid) {
+			continue
+		}
+		misses = append(misses, uid)
+	}
+	a.log.DebugContext(ctx, "batch load", "orders", len(uids), "cache_misses", len(misses))
+
+	if len(misses) > 0 {
+		loaded, err := a.repo.GetOrders(ctx, misses)
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range loaded {
+			a.cache.Set(o.OrderUID, o)
+			found[o.OrderUID] = o
+		}
+		for _, uid := range misses {
+			if _, ok := found[uid]; !ok {
+				a.notFound.add(uid)
+			}
+		}
+	}
+
+	orders := make([]repo.Order, 0, len(found))
+	for _, uid := range uids {
+		if o, ok := found[uid]; ok {
+			orders = append(orders, o)
+		}
+	}
+	return orders, nil
 }
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -17,17 +18,61 @@ import (
 )
 
 type fakeRepo struct {
-	Order repo.Order
-	Err   error
+	Order  repo.Order
+	Err    error
+	Page   repo.OrdersPage
+	Filter *repo.OrderFilter
+	UIDs   []string
+	Lookup *repo.LookupKey
+	Hits   []repo.SearchHit
+	Loads  *[][]string
 }
 
 func (f fakeRepo) GetOrder(ctx context.Context, id string) (repo.Order, error) {
 	return f.Order, f.Err
 }
 
-type nopLogger struct{}
+func (f fakeRepo) GetOrders(ctx context.Context, uids []string) ([]repo.Order, error) {
+	if f.Loads != nil {
+		*f.Loads = append(*f.Loads, uids)
+	}
+	if f.Err != nil && !errors.Is(f.Err, repo.ErrNotFound) {
+		return nil, f.Err
+	}
+	var out []repo.Order
+	for _, uid := range uids {
+		if uid == f.Order.OrderUID {
+			out = append(out, f.Order)
+		}
+	}
+	return out, nil
+}
+
+func (f fakeRepo) ListOrders(ctx context.Context, flt repo.OrderFilter) (repo.OrdersPage, error) {
+	if f.Filter != nil {
+		*f.Filter = flt
+	}
+	return f.Page, f.Err
+}
+
+func (f fakeRepo) FindOrderUIDs(ctx context.Context, key repo.LookupKey, value string, limit int) ([]string, error) {
+	if f.Lookup != nil {
+		*f.Lookup = key
+	}
+	if value == "" {
+		return nil, repo.ErrBadLookup
+	}
+	return f.UIDs, nil
+}
+
+func (f fakeRepo) SearchOrders(ctx context.Context, q string, limit int) ([]repo.SearchHit, error) {
+	if q == "" {
+		return nil, repo.ErrBadQuery
+	}
+	return f.Hits, f.Err
+}
 
-func (nopLogger) Printf(string, ...any) {}
+var discard = slog.New(slog.DiscardHandler)
 
 func doJSON(t *testing.T, h http.Handler, method, path string, body io.Reader, hdr map[string]string) (*httptest.ResponseRecorder, map[string]any) {
 	t.Helper()
@@ -45,8 +90,13 @@ func doJSON(t *testing.T, h http.Handler, method, path string, body io.Reader, h
 	}
 	return rr, m
 }
+
+const testAdminToken = "s3cret"
+
+var adminHdr = map[string]string{"Authorization": "Bearer " + testAdminToken}
+
 func newAPI(src OrderSource, c *cache.OrdersCache) *OrdersAPI {
-	return New(src, c, nopLogger{}.Printf, "testver")
+	return New(src, c, discard, "testver", WithAdminToken(testAdminToken))
 }
 
 func TestRootRedirectAndNotFound(t *testing.T) {
@@ -76,6 +126,7 @@ func TestHealthz_OK_and_MethodNotAllowed(t *testing.T) {
 	require.Equal(t, "ok", m["status"])
 	require.Equal(t, "testver", m["version"])
 	require.Equal(t, "rid-123", m["request_id"])
+	require.Contains(t, m["cache"], "hit_ratio")
 
 	rr, m = doJSON(t, h, http.MethodPost, "/healthz", bytes.NewBufferString("{}"), nil)
 	require.Equal(t, http.StatusMethodNotAllowed, rr.Code)
@@ -163,6 +214,28 @@ func TestOrder_DBBranches(t *testing.T) {
 	require.Equal(t, order, got)
 }
 
+type recordedAccess []string
+
+func (r *recordedAccess) Record(uid string) { *r = append(*r, uid) }
+
+func TestOrder_RecordsSuccessfulAccess(t *testing.T) {
+	t.Parallel()
+	c := cache.New()
+	c.Set("u1", repo.Order{OrderUID: "u1"})
+	var rec recordedAccess
+	api := New(fakeRepo{Err: repo.ErrNotFound}, c, discard, "testver", WithAccessRecorder(&rec))
+	h := api.Routes()
+
+	rr, _ := doJSON(t, h, http.MethodGet, "/order/u1", nil, nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	rr, _ = doJSON(t, h, http.MethodGet, "/order/missing", nil, nil)
+	require.Equal(t, http.StatusNotFound, rr.Code)
+	rr, _ = doJSON(t, h, http.MethodGet, "/order/u1", nil, nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	require.Equal(t, recordedAccess{"u1", "u1"}, rec, "учитываются только найденные заказы")
+}
+
 func TestOrder_PathWithExtraSegments_Trimmed(t *testing.T) {
 	t.Parallel()
 	c := cache.New()
@@ -4,7 +4,12 @@ import (
 	"context"
 	crand "crypto/rand"
 	"encoding/hex"
+	"log/slog"
 	"net/http"
+
+	"github.com/mrussa/L0/internal/logging"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type ctxKey string
@@ -14,13 +19,20 @@ const headerRequestID = "X-Request-ID"
 
 func WithRequestID(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		span := trace.SpanFromContext(r.Context())
 		id := r.Header.Get(headerRequestID)
 		if id == "" {
-			id = newID()
+			if sc := span.SpanContext(); sc.HasTraceID() {
+				id = sc.TraceID().String()
+			} else {
+				id = newID()
+			}
 		}
 		w.Header().Set(headerRequestID, id)
+		span.SetAttributes(attribute.String("request.id", id))
 
 		ctx := context.WithValue(r.Context(), requestIDKey, id)
+		ctx = logging.WithAttrs(ctx, slog.String("request_id", id))
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
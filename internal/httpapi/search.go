@@ -0,0 +1,42 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/mrussa/L0/internal/repo"
+	"github.com/mrussa/L0/internal/respond"
+)
+
+func (a *OrdersAPI) handleSearch(w http.ResponseWriter, r *http.Request) {
+	reqID := RequestID(r)
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		respond.ErrorWithID(w, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed", reqID)
+		return
+	}
+
+	q := r.URL.Query().Get("q")
+	limit := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxListLimit {
+			respond.ErrorWithID(w, http.StatusBadRequest, "bad_request", "bad limit", reqID)
+			return
+		}
+		limit = n
+	}
+
+	hits, err := a.repo.SearchOrders(r.Context(), q, limit)
+	if err != nil {
+		if errors.Is(err, repo.ErrBadQuery) {
+			respond.ErrorWithID(w, http.StatusBadRequest, "bad_request", "bad search query", reqID)
+			return
+		}
+		a.log.ErrorContext(r.Context(), "search failed", "query", q, "err", err)
+		respond.ErrorWithID(w, http.StatusInternalServerError, "internal", "internal error", reqID)
+		return
+	}
+	respond.JSON(w, http.StatusOK, map[string]any{"query": q, "hits": hits})
+}
@@ -0,0 +1,49 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/mrussa/L0/internal/cache"
+	"github.com/mrussa/L0/internal/repo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearch_ReturnsHits(t *testing.T) {
+	t.Parallel()
+	hits := []repo.SearchHit{{
+		OrderSummary: repo.OrderSummary{OrderUID: "u1"},
+		Rank:         0.5,
+		Headline:     "<mark>Kiryat</mark> Mozkin",
+	}}
+	h := newAPI(fakeRepo{Hits: hits}, cache.New()).Routes()
+
+	rr, m := doJSON(t, h, http.MethodGet, "/orders/search?q=kiryat&limit=5", nil, nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, "kiryat", m["query"])
+	got := m["hits"].([]any)[0].(map[string]any)
+	require.Equal(t, "u1", got["order_uid"])
+	require.Equal(t, "<mark>Kiryat</mark> Mozkin", got["headline"])
+}
+
+func TestSearch_Errors(t *testing.T) {
+	t.Parallel()
+	h := newAPI(fakeRepo{}, cache.New()).Routes()
+
+	rr, m := doJSON(t, h, http.MethodGet, "/orders/search", nil, nil)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	require.Equal(t, "bad search query", m["message"])
+
+	rr, m = doJSON(t, h, http.MethodGet, "/orders/search?q=x&limit=500", nil, nil)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	require.Equal(t, "bad limit", m["message"])
+
+	rr, _ = doJSON(t, h, http.MethodPost, "/orders/search?q=x", nil, nil)
+	require.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+
+	h = newAPI(fakeRepo{Err: errors.New("boom")}, cache.New()).Routes()
+	rr, m = doJSON(t, h, http.MethodGet, "/orders/search?q=x", nil, nil)
+	require.Equal(t, http.StatusInternalServerError, rr.Code)
+	require.Equal(t, "internal", m["error"])
+}
@@ -0,0 +1,87 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/mrussa/L0/internal/cache"
+	"github.com/mrussa/L0/internal/repo"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+var (
+	spansOnce sync.Once
+	spans     *tracetest.SpanRecorder
+)
+
+func recordSpans() *tracetest.SpanRecorder {
+	spansOnce.Do(func() {
+		spans = tracetest.NewSpanRecorder()
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)))
+		otel.SetTextMapPropagator(propagation.TraceContext{})
+	})
+	return spans
+}
+
+func spansOfTrace(rec *tracetest.SpanRecorder, traceID string) []sdktrace.ReadOnlySpan {
+	var out []sdktrace.ReadOnlySpan
+	for _, s := range rec.Ended() {
+		if s.SpanContext().TraceID().String() == traceID {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func spanAttr(s sdktrace.ReadOnlySpan, key attribute.Key) attribute.Value {
+	for _, kv := range s.Attributes() {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestTracing_ExtractsParentAndLinksRequestID(t *testing.T) {
+	rec := recordSpans()
+	c := cache.New()
+	c.Set("u1", repo.Order{OrderUID: "u1"})
+	h := newAPI(fakeRepo{Err: errors.New("boom")}, c).Routes()
+
+	traceID := newID()
+	parent := map[string]string{"traceparent": "00-" + traceID + "-00f067aa0ba902b7-01"}
+
+	rr, _ := doJSON(t, h, http.MethodGet, "/order/u1", nil, parent)
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, traceID, rr.Header().Get(headerRequestID), "без X-Request-ID используется trace id")
+
+	got := spansOfTrace(rec, traceID)
+	require.Len(t, got, 1)
+	s := got[0]
+	require.Equal(t, "GET /order/", s.Name())
+	require.Equal(t, "00f067aa0ba902b7", s.Parent().SpanID().String())
+	require.Equal(t, "/order/", spanAttr(s, "http.route").AsString())
+	require.EqualValues(t, http.StatusOK, spanAttr(s, "http.response.status_code").AsInt64())
+	require.Equal(t, traceID, spanAttr(s, "request.id").AsString())
+
+	otherTrace := newID()
+	rr, _ = doJSON(t, h, http.MethodGet, "/order/u2", nil, map[string]string{
+		"traceparent":   "00-" + otherTrace + "-b7ad6b7169203331-01",
+		headerRequestID: "client-id",
+	})
+	require.Equal(t, http.StatusInternalServerError, rr.Code)
+	require.Equal(t, "client-id", rr.Header().Get(headerRequestID))
+
+	got = spansOfTrace(rec, otherTrace)
+	require.Len(t, got, 1)
+	require.Equal(t, "client-id", spanAttr(got[0], "request.id").AsString())
+	require.Equal(t, codes.Error, got[0].Status().Code)
+}
@@ -0,0 +1,105 @@
+package kafka
+
+import (
+	"context"
+	"time"
+
+	"github.com/mrussa/L0/internal/repo"
+	"github.com/segmentio/kafka-go"
+)
+
+func (c *Consumer) runLane(ctx context.Context, r committer, in <-chan kafka.Message, fail context.CancelCauseFunc) {
+	size := max(c.BatchSize, 1)
+	batch := make([]kafka.Message, 0, size)
+
+	var (
+		timer  *time.Timer
+		expire <-chan time.Time
+	)
+	flush := func() {
+		if timer != nil {
+			timer.Stop()
+			expire = nil
+		}
+		if len(batch) == 0 {
+			return
+		}
+		if ctx.Err() == nil {
+			if err := c.handleBatch(ctx, r, batch); err != nil {
+				fail(err)
+			}
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case msg, ok := <-in:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, msg)
+			switch {
+			case len(batch) >= size:
+				flush()
+			case len(batch) == 1:
+				timer = time.NewTimer(c.BatchWait)
+				expire = timer.C
+			}
+		case <-expire:
+			flush()
+		}
+	}
+}
+
+func (c *Consumer) handleBatch(ctx context.Context, r committer, msgs []kafka.Message) (err error) {
+	if len(msgs) == 1 {
+		return c.handleMessage(ctx, r, msgs[0])
+	}
+	ctx, span := startBatchSpan(ctx, msgs)
+	defer func() { endSpan(span, err) }()
+
+	valid := make([]kafka.Message, 0, len(msgs))
+	orders := make([]repo.Order, 0, len(msgs))
+	for _, msg := range msgs {
+		ord, ok, err := c.prepare(ctx, r, msg)
+		if err != nil {
+			return err
+		}
+		if ok {
+			valid = append(valid, msg)
+			orders = append(orders, ord)
+		}
+	}
+	if len(orders) == 0 {
+		return nil
+	}
+
+	start := time.Now()
+	err = c.Repo.UpsertOrders(ctx, orders)
+	c.metrics().ObserveUpsert(true, time.Since(start))
+	if err == nil {
+		for _, ord := range orders {
+			c.Cache.Set(ord.OrderUID, ord)
+		}
+		c.metrics().Messages("stored", len(orders))
+		c.logger().InfoContext(ctx, "batch stored", "orders", len(orders))
+		if err := r.CommitMessages(ctx, valid...); err != nil {
+			c.logger().WarnContext(ctx, "batch commit failed", "messages", len(valid), "err", err)
+		}
+		return nil
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+
+	c.logger().WarnContext(ctx, "batch upsert failed, falling back to one by one", "orders", len(orders), "err", err)
+	span.AddEvent("batch upsert failed, falling back to one by one")
+	for i, msg := range valid {
+		if err := c.store(ctx, r, msg, orders[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,267 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mrussa/L0/internal/codec"
+	"github.com/mrussa/L0/internal/repo"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/require"
+)
+
+type batchRepo struct {
+	mu       sync.Mutex
+	batches  [][]string
+	single   []string
+	batchErr error
+	badUID   string
+}
+
+func (b *batchRepo) UpsertOrder(ctx context.Context, o repo.Order) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if o.OrderUID == b.badUID {
+		return errors.New("constraint violation")
+	}
+	b.single = append(b.single, o.OrderUID)
+	return nil
+}
+
+func (b *batchRepo) UpsertOrders(ctx context.Context, orders []repo.Order) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.batchErr != nil {
+		return b.batchErr
+	}
+	uids := make([]string, 0, len(orders))
+	for _, o := range orders {
+		uids = append(uids, o.OrderUID)
+	}
+	b.batches = append(b.batches, uids)
+	return nil
+}
+
+func orderMsg(t *testing.T, uid string, off int64) kafka.Message {
+	t.Helper()
+	ord := validOrder()
+	ord.OrderUID = uid
+	return kafka.Message{Topic: "t", Offset: off, Key: []byte(uid), Value: toJSON(t, ord)}
+}
+
+func newBatchConsumer(r orderStore) *Consumer {
+	return &Consumer{
+		Repo:          r,
+		Cache:         &syncCache{keys: map[string]int{}},
+		Logger:        discard,
+		Decode:        codec.JSON,
+		Validate:      defaultValidate,
+		RetryAttempts: 1,
+	}
+}
+
+func Test_handleBatch_OneTransaction_CommitsTogether(t *testing.T) {
+	br := &batchRepo{}
+	c := newBatchConsumer(br)
+	fr := &fakeReader{}
+
+	msgs := []kafka.Message{orderMsg(t, "a", 0), orderMsg(t, "b", 1), orderMsg(t, "c", 2)}
+	require.NoError(t, c.handleBatch(context.Background(), fr, msgs))
+
+	require.Equal(t, [][]string{{"a", "b", "c"}}, br.batches)
+	require.Empty(t, br.single)
+	require.Equal(t, 1, fr.commitCalls, "оффсеты батча коммитятся одним вызовом")
+	require.Len(t, fr.commits, 3)
+	require.Equal(t, 3, len(c.Cache.(*syncCache).keys))
+}
+
+func Test_handleBatch_InvalidMessagesRejectedSeparately(t *testing.T) {
+	br := &batchRepo{}
+	c := newBatchConsumer(br)
+	fr := &fakeReader{}
+
+	bad := kafka.Message{Topic: "t", Offset: 1, Value: []byte("{")}
+	msgs := []kafka.Message{orderMsg(t, "a", 0), bad, orderMsg(t, "c", 2)}
+	require.NoError(t, c.handleBatch(context.Background(), fr, msgs))
+
+	require.Equal(t, [][]string{{"a", "c"}}, br.batches)
+	require.Len(t, fr.commits, 3)
+}
+
+func Test_handleBatch_AllInvalid_NoUpsert(t *testing.T) {
+	br := &batchRepo{}
+	c := newBatchConsumer(br)
+	fr := &fakeReader{}
+
+	msgs := []kafka.Message{{Topic: "t", Offset: 0, Value: []byte("{")}, {Topic: "t", Offset: 1, Value: []byte("[")}}
+	require.NoError(t, c.handleBatch(context.Background(), fr, msgs))
+	require.Empty(t, br.batches)
+	require.Len(t, fr.commits, 2)
+}
+
+func Test_handleBatch_Fallback_OneBadOrderDoesNotBlockOthers(t *testing.T) {
+	br := &batchRepo{batchErr: errors.New("batch failed"), badUID: "b"}
+	c := newBatchConsumer(br)
+	c.OnRetryExhausted = ExhaustDLQ
+	fw := &fakeWriter{}
+	c.dlq = fw
+	fr := &fakeReader{}
+
+	msgs := []kafka.Message{orderMsg(t, "a", 0), orderMsg(t, "b", 1), orderMsg(t, "c", 2)}
+	require.NoError(t, c.handleBatch(context.Background(), fr, msgs))
+
+	require.Equal(t, []string{"a", "c"}, br.single)
+	require.Len(t, fw.msgs, 1)
+	require.Equal(t, "1", headerMap(fw.msgs[0].Headers)[hdrOrigOffset])
+	require.Len(t, fr.commits, 3)
+}
+
+func Test_handleBatch_Fallback_HaltStopsAtBadOrder(t *testing.T) {
+	br := &batchRepo{batchErr: errors.New("batch failed"), badUID: "b"}
+	c := newBatchConsumer(br)
+	c.OnRetryExhausted = ExhaustHalt
+	fr := &fakeReader{}
+
+	msgs := []kafka.Message{orderMsg(t, "a", 0), orderMsg(t, "b", 1), orderMsg(t, "c", 2)}
+	err := c.handleBatch(context.Background(), fr, msgs)
+	require.ErrorIs(t, err, ErrHalted)
+	require.Equal(t, []string{"a"}, br.single)
+	require.Len(t, fr.commits, 1)
+}
+
+func Test_handleBatch_CanceledDuringBatch_NoFallback(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	br := &batchRepo{batchErr: context.Canceled}
+	c := newBatchConsumer(br)
+	fr := &fakeReader{}
+
+	err := c.handleBatch(ctx, fr, []kafka.Message{orderMsg(t, "a", 0), orderMsg(t, "b", 1)})
+	require.ErrorIs(t, err, context.Canceled)
+	require.Empty(t, br.single)
+	require.Empty(t, fr.commits)
+}
+
+func Test_runLane_FlushesBySizeAndByTime(t *testing.T) {
+	br := &batchRepo{}
+	c := newBatchConsumer(br)
+	c.BatchSize = 2
+	c.BatchWait = 20 * time.Millisecond
+	fr := &fakeReader{}
+
+	in := make(chan kafka.Message, 4)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c.runLane(context.Background(), fr, in, func(error) {})
+	}()
+
+	in <- orderMsg(t, "a", 0)
+	in <- orderMsg(t, "b", 1)
+	in <- orderMsg(t, "c", 2)
+
+	require.Eventually(t, func() bool {
+		br.mu.Lock()
+		defer br.mu.Unlock()
+		return len(br.single) == 1
+	}, time.Second, 5*time.Millisecond, "хвост из одного сообщения уходит по таймеру")
+
+	close(in)
+	<-done
+	require.Equal(t, [][]string{{"a", "b"}}, br.batches)
+	require.Equal(t, []string{"c"}, br.single)
+}
+
+func Test_runLane_ReportsHalt(t *testing.T) {
+	br := &batchRepo{batchErr: errors.New("batch failed"), badUID: "a"}
+	c := newBatchConsumer(br)
+	c.BatchSize = 10
+	c.BatchWait = time.Hour
+
+	in := make(chan kafka.Message, 2)
+	in <- orderMsg(t, "a", 0)
+	in <- orderMsg(t, "b", 1)
+	close(in)
+
+	var got error
+	c.runLane(context.Background(), &fakeReader{}, in, func(err error) { got = err })
+	require.ErrorIs(t, got, ErrHalted)
+}
+
+func Test_Run_Batched_CommitsEverything(t *testing.T) {
+	var steps []step
+	for i := int64(0); i < 7; i++ {
+		steps = append(steps, step{msg: orderMsg(t, string(rune('a'+i)), i)})
+	}
+	steps = append(steps, step{err: context.Canceled})
+	fr := &fakeReader{steps: steps}
+	br := &batchRepo{}
+
+	err := withReader(t, fr, func(c *Consumer) error {
+		c.Repo = br
+		c.Cache = &syncCache{keys: map[string]int{}}
+		c.Workers = 1
+		c.BatchSize = 3
+		c.BatchWait = time.Hour
+		return c.Run(context.Background())
+	})
+	require.ErrorIs(t, err, context.Canceled)
+
+	require.Equal(t, [][]string{{"a", "b", "c"}, {"d", "e", "f"}}, br.batches)
+	require.Equal(t, []string{"g"}, br.single)
+	require.Equal(t, int64(6), fr.commits[len(fr.commits)-1].Offset)
+}
+
+type countingMetrics struct {
+	mu      sync.Mutex
+	stages  map[string]int
+	upserts map[bool]int
+	lag     map[int]int64
+}
+
+func (m *countingMetrics) Messages(stage string, n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.stages[stage] += n
+}
+
+func (m *countingMetrics) SetLag(partition int, lag int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lag[partition] = lag
+}
+
+func (m *countingMetrics) ObserveUpsert(batch bool, d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.upserts[batch]++
+}
+
+func Test_Metrics_CountStages(t *testing.T) {
+	m := &countingMetrics{stages: map[string]int{}, upserts: map[bool]int{}, lag: map[int]int64{}}
+	br := &batchRepo{}
+	c := newBatchConsumer(br)
+	c.Metrics = m
+
+	bad := orderMsg(t, "x", 2)
+	bad.Value = []byte(`{"order_uid":"x"}`)
+	msgs := []kafka.Message{orderMsg(t, "a", 0), orderMsg(t, "b", 1), bad, {Topic: "t", Offset: 3, Value: []byte("{")}}
+	require.NoError(t, c.handleBatch(context.Background(), &fakeReader{}, msgs))
+	require.NoError(t, c.handleMessage(context.Background(), &fakeReader{}, orderMsg(t, "c", 4)))
+
+	require.Equal(t, map[string]int{"decoded": 4, "invalid": 1, "stored": 3}, m.stages)
+	require.Equal(t, map[bool]int{true: 1, false: 1}, m.upserts)
+
+	fr := &fakeReader{steps: []step{
+		{msg: kafka.Message{Partition: 0, Offset: 5, HighWaterMark: 10}},
+		{msg: kafka.Message{Partition: 1, Offset: 7, HighWaterMark: 8}},
+	}}
+	lanes := []chan kafka.Message{make(chan kafka.Message, 2)}
+	err := c.dispatch(context.Background(), fr, newOffsetTracker(fr), lanes)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, 2, m.stages["consumed"])
+	require.Equal(t, map[int]int64{0: 4, 1: 0}, m.lag)
+}
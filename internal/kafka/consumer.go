@@ -2,35 +2,57 @@ package kafka
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
-	"fmt"
-	"math/rand"
+	"hash/fnv"
+	"log/slog"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/mrussa/L0/internal/codec"
 	"github.com/mrussa/L0/internal/repo"
+	"github.com/mrussa/L0/internal/validate"
 	"github.com/segmentio/kafka-go"
 )
 
 type orderStore interface {
 	UpsertOrder(ctx context.Context, o repo.Order) error
+	UpsertOrders(ctx context.Context, orders []repo.Order) error
 }
 
 type OrderCache interface {
 	Set(key string, o repo.Order)
 }
 
+type Metrics interface {
+	Messages(stage string, n int)
+	SetLag(partition int, lag int64)
+	ObserveUpsert(batch bool, d time.Duration)
+}
+
+type nopMetrics struct{}
+
+func (nopMetrics) Messages(string, int)              {}
+func (nopMetrics) SetLag(int, int64)                 {}
+func (nopMetrics) ObserveUpsert(bool, time.Duration) {}
+
 type reader interface {
+	committer
 	FetchMessage(context.Context) (kafka.Message, error)
-	CommitMessages(context.Context, ...kafka.Message) error
 	Close() error
 }
 
 const (
-	minBytes  = 1
-	maxBytes  = 10 * 1024 * 1024
-	retryBase = 300 * time.Millisecond
+	minBytes      = 1
+	maxBytes      = 10 * 1024 * 1024
+	retryBase     = 300 * time.Millisecond
+	retryMax      = 10 * time.Second
+	retryAttempts = 5
+	workers       = 4
+	laneBuffer    = 16
+	batchSize     = 100
+	batchWait     = 50 * time.Millisecond
 )
 
 var newReader = func(cfg kafka.ReaderConfig) reader { return kafka.NewReader(cfg) }
@@ -38,56 +60,64 @@ var newReader = func(cfg kafka.ReaderConfig) reader { return kafka.NewReader(cfg
 type Decoder func([]byte, *repo.Order) error
 type Validator func(*repo.Order) error
 
-func defaultDecode(b []byte, o *repo.Order) error { return json.Unmarshal(b, o) }
+var basicRules = validate.MustParse("required,amount")
 
 func defaultValidate(o *repo.Order) error {
-	if o.OrderUID == "" {
-		return fmt.Errorf("field order_uid: empty")
-	}
-	if len(o.OrderUID) > 100 {
-		return fmt.Errorf("field order_uid: too long")
-	}
-	if o.TrackNumber == "" {
-		return fmt.Errorf("field track_number: empty")
-	}
-	if o.Payment.Currency == "" {
-		return fmt.Errorf("field currency: empty")
-	}
-	if o.Payment.Amount < 0 {
-		return fmt.Errorf("field amount: negative")
-	}
-	return nil
+	return basicRules.Validate(o)
 }
 
 type Consumer struct {
-	Brokers []string
-	Topic   string
-	Group   string
+	Brokers  []string
+	Topic    string
+	Group    string
+	DLQTopic string
 
 	Repo  orderStore
 	Cache OrderCache
 
-	Logf     func(string, ...any)
+	Logger   *slog.Logger
 	Decode   Decoder
+	Decoders map[codec.Format]Decoder
 	Validate Validator
+	Metrics  Metrics
+
+	RetryBase        time.Duration
+	RetryMax         time.Duration
+	RetryAttempts    int
+	OnRetryExhausted ExhaustPolicy
+
+	Workers   int
+	BatchSize int
+	BatchWait time.Duration
 
-	RetryBase time.Duration
+	dlq    writer
+	health healthState
 }
 
-func NewConsumer(brokersCSV, topic, group string, r *repo.OrdersRepo, c OrderCache, logf func(string, ...any)) *Consumer {
-	if logf == nil {
-		logf = func(string, ...any) {}
+func NewConsumer(brokersCSV, topic, group string, r *repo.OrdersRepo, c OrderCache, log *slog.Logger) *Consumer {
+	if log == nil {
+		log = slog.New(slog.DiscardHandler)
 	}
 	return &Consumer{
-		Brokers:   splitCSV(brokersCSV),
-		Topic:     topic,
-		Group:     group,
-		Repo:      r,
-		Cache:     c,
-		Logf:      logf,
-		Decode:    defaultDecode,
-		Validate:  defaultValidate,
-		RetryBase: retryBase,
+		Brokers:  splitCSV(brokersCSV),
+		Topic:    topic,
+		Group:    group,
+		Repo:     r,
+		Cache:    c,
+		Logger:   log,
+		Decode:   codec.JSON,
+		Decoders: map[codec.Format]Decoder{codec.FormatJSON: codec.JSON},
+		Validate: defaultValidate,
+		Metrics:  nopMetrics{},
+
+		RetryBase:        retryBase,
+		RetryMax:         retryMax,
+		RetryAttempts:    retryAttempts,
+		OnRetryExhausted: ExhaustHalt,
+
+		Workers:   workers,
+		BatchSize: batchSize,
+		BatchWait: batchWait,
 	}
 }
 
@@ -102,60 +132,165 @@ func (c *Consumer) Run(ctx context.Context) error {
 	})
 	defer r.Close()
 
-	c.Logf("[KAFKA] reader connected (group=%s topic=%s brokers=%v)", c.Group, c.Topic, c.Brokers)
+	c.health.start(time.Now())
+	if c.DLQTopic != "" {
+		w := newWriter(c.Brokers, c.DLQTopic)
+		defer w.Close()
+		c.dlq = w
+	}
+
+	workers := max(c.Workers, 1)
+	c.logger().InfoContext(ctx, "reader connected", "group", c.Group, "topic", c.Topic, "brokers", c.Brokers, "workers", workers)
+
+	runCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	tr := newOffsetTracker(r)
+	tr.onCommit = func() { c.health.committed(time.Now()) }
+	lanes := make([]chan kafka.Message, workers)
+	var wg sync.WaitGroup
+	for i := range lanes {
+		lanes[i] = make(chan kafka.Message, laneBuffer)
+		wg.Add(1)
+		go func(in <-chan kafka.Message) {
+			defer wg.Done()
+			c.runLane(runCtx, tr, in, cancel)
+		}(lanes[i])
+	}
+
+	err := c.dispatch(runCtx, r, tr, lanes)
+
+	for _, l := range lanes {
+		close(l)
+	}
+	wg.Wait()
+
+	if ctx.Err() == nil {
+		if cause := context.Cause(runCtx); cause != nil {
+			err = cause
+		}
+	}
+	c.health.stop(err)
+	c.logger().InfoContext(ctx, "consumer stopped", "err", err)
+	return err
+}
 
+func (c *Consumer) dispatch(ctx context.Context, r reader, tr *offsetTracker, lanes []chan kafka.Message) error {
 	for {
 		msg, err := r.FetchMessage(ctx)
 		if err != nil {
-			if errors.Is(err, context.Canceled) {
-				c.Logf("[KAFKA] stopped: %v", err)
-				return err
+			if !errors.Is(err, context.Canceled) {
+				c.logger().ErrorContext(ctx, "fetch failed", "err", err)
 			}
-			c.Logf("[KAFKA] fetch error: %v", err)
 			return err
 		}
-		c.handleMessage(ctx, r, msg)
+
+		c.health.fetched(time.Now())
+		c.metrics().Messages("consumed", 1)
+		c.metrics().SetLag(msg.Partition, msg.HighWaterMark-msg.Offset-1)
+		tr.Track(msg)
+		select {
+		case lanes[laneOf(msg, len(lanes))] <- msg:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func laneOf(msg kafka.Message, n int) int {
+	if n <= 1 {
+		return 0
+	}
+	h := fnv.New32a()
+	if len(msg.Key) > 0 {
+		_, _ = h.Write(msg.Key)
+	} else {
+		_, _ = h.Write([]byte(strconv.Itoa(msg.Partition)))
+	}
+	return int(h.Sum32() % uint32(n))
+}
+
+func (c *Consumer) handleMessage(ctx context.Context, r committer, msg kafka.Message) (err error) {
+	ctx, span := startMessageSpan(ctx, msg)
+	defer func() { endSpan(span, err) }()
+
+	ord, ok, err := c.prepare(ctx, r, msg)
+	if !ok {
+		return err
 	}
+	return c.store(ctx, r, msg, ord)
 }
 
-func (c *Consumer) handleMessage(ctx context.Context, r reader, msg kafka.Message) {
+func (c *Consumer) prepare(ctx context.Context, r committer, msg kafka.Message) (repo.Order, bool, error) {
 	var ord repo.Order
 
-	if err := c.Decode(msg.Value, &ord); err != nil {
-		c.Logf("[KAFKA] bad json %s[%d]#%d: %v", msg.Topic, msg.Partition, msg.Offset, err)
-		_ = r.CommitMessages(ctx, msg)
-		return
+	dec, err := c.decoderFor(msg)
+	if err == nil {
+		err = dec(msg.Value, &ord)
 	}
+	if err != nil {
+		attrs := []any{msgAttr(msg), "err", err}
+		var de *codec.DecodeError
+		if errors.As(err, &de) {
+			attrs = append(attrs, "violations", de.Strings())
+		}
+		c.logger().WarnContext(ctx, "bad payload", attrs...)
+		return repo.Order{}, false, c.reject(ctx, r, msg, stageDecode, err)
+	}
+	c.metrics().Messages("decoded", 1)
 
 	if len(msg.Key) > 0 && string(msg.Key) != ord.OrderUID {
-		c.Logf("[KAFKA] key/payload mismatch %s[%d]#%d: key=%q payload=%q",
-			msg.Topic, msg.Partition, msg.Offset, string(msg.Key), ord.OrderUID)
+		c.logger().WarnContext(ctx, "key/payload mismatch", msgAttr(msg), "key", string(msg.Key), "order_uid", ord.OrderUID)
 	}
 
 	if err := c.Validate(&ord); err != nil {
-		c.Logf("[KAFKA] invalid %q %s[%d]#%d: %v",
-			ord.OrderUID, msg.Topic, msg.Partition, msg.Offset, err)
-		_ = r.CommitMessages(ctx, msg)
-		return
+		attrs := []any{msgAttr(msg), "order_uid", ord.OrderUID, "err", err}
+		var ve *validate.Error
+		if errors.As(err, &ve) {
+			attrs = append(attrs, "violations", ve.Strings())
+		}
+		c.logger().WarnContext(ctx, "invalid order", attrs...)
+		c.metrics().Messages("invalid", 1)
+		return repo.Order{}, false, c.reject(ctx, r, msg, stageValidate, err)
 	}
+	return ord, true, nil
+}
 
-	if err := c.Repo.UpsertOrder(ctx, ord); err != nil {
-		c.Logf("[KAFKA] upsert %s: %v", ord.OrderUID, err)
-		c.backoff()
-		return
+func (c *Consumer) store(ctx context.Context, r committer, msg kafka.Message, ord repo.Order) error {
+	if err := c.upsertWithRetry(ctx, ord); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			c.logger().InfoContext(ctx, "upsert aborted", "order_uid", ord.OrderUID, "err", ctxErr)
+			return ctxErr
+		}
+		return c.retriesExhausted(ctx, r, msg, ord, err)
 	}
 
 	c.Cache.Set(ord.OrderUID, ord)
-	c.Logf("[KAFKA] stored %s (items=%d)", ord.OrderUID, len(ord.Items))
+	c.metrics().Messages("stored", 1)
+	c.logger().InfoContext(ctx, "order stored", "order_uid", ord.OrderUID, "items", len(ord.Items))
 
 	if err := r.CommitMessages(ctx, msg); err != nil {
-		c.Logf("[KAFKA] commit error %s[%d]#%d: %v", msg.Topic, msg.Partition, msg.Offset, err)
+		c.logger().WarnContext(ctx, "commit failed", msgAttr(msg), "err", err)
+	}
+	return nil
+}
+
+func (c *Consumer) metrics() Metrics {
+	if c.Metrics == nil {
+		return nopMetrics{}
+	}
+	return c.Metrics
+}
+
+func (c *Consumer) logger() *slog.Logger {
+	if c.Logger == nil {
+		return slog.New(slog.DiscardHandler)
 	}
+	return c.Logger
 }
 
-func (c *Consumer) backoff() {
-	j := time.Duration(rand.Intn(200)) * time.Millisecond
-	time.Sleep(c.RetryBase + j)
+func msgAttr(msg kafka.Message) slog.Attr {
+	return slog.Group("kafka", "topic", msg.Topic, "partition", msg.Partition, "offset", msg.Offset)
 }
 
 func splitCSV(s string) []string {
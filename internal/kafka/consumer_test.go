@@ -1,18 +1,25 @@
 package kafka
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/mrussa/L0/internal/codec"
 	"github.com/mrussa/L0/internal/repo"
 	pgxmock "github.com/pashagolub/pgxmock/v4"
 	"github.com/segmentio/kafka-go"
 	"github.com/stretchr/testify/require"
 )
 
+var discard = slog.New(slog.DiscardHandler)
+
 type step struct {
 	msg kafka.Message
 	err error
@@ -25,6 +32,7 @@ type stubRepo struct {
 }
 
 type fakeReader struct {
+	mu          sync.Mutex
 	steps       []step
 	i           int
 	closed      bool
@@ -46,6 +54,8 @@ func (f *fakeReader) FetchMessage(ctx context.Context) (kafka.Message, error) {
 }
 
 func (f *fakeReader) CommitMessages(ctx context.Context, msgs ...kafka.Message) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
 	f.commits = append(f.commits, msgs...)
 	f.commitCalls++
 	if f.commitErrAt != 0 && f.commitCalls == f.commitErrAt {
@@ -103,16 +113,24 @@ func Test_splitCSV(t *testing.T) {
 func Test_NewConsumer(t *testing.T) {
 	r := &repo.OrdersRepo{}
 	fc := &fakeCache{}
-	got := NewConsumer("k1:9092,  k2:9092 , ,", "topic", "group", r, fc, func(string, ...any) {})
+	got := NewConsumer("k1:9092,  k2:9092 , ,", "topic", "group", r, fc, discard)
 	require.Equal(t, []string{"k1:9092", "k2:9092"}, got.Brokers)
 	require.Equal(t, "topic", got.Topic)
 	require.Equal(t, "group", got.Group)
 	require.Same(t, r, got.Repo)
 	require.Same(t, fc, got.Cache)
-	require.NotNil(t, got.Logf)
+	require.NotNil(t, got.Logger)
 	require.NotNil(t, got.Decode)
+	require.Contains(t, got.Decoders, codec.FormatJSON)
 	require.NotNil(t, got.Validate)
+	require.Equal(t, nopMetrics{}, got.Metrics)
 	require.Equal(t, retryBase, got.RetryBase)
+	require.Equal(t, retryMax, got.RetryMax)
+	require.Equal(t, retryAttempts, got.RetryAttempts)
+	require.Equal(t, ExhaustHalt, got.OnRetryExhausted)
+	require.Equal(t, workers, got.Workers)
+	require.Equal(t, batchSize, got.BatchSize)
+	require.Equal(t, batchWait, got.BatchWait)
 }
 
 func Test_defaultValidate_OK_and_Errors(t *testing.T) {
@@ -152,8 +170,8 @@ func withReader(t *testing.T, fr *fakeReader, fn func(*Consumer) error) error {
 		Group:     "g",
 		Repo:      &repo.OrdersRepo{},
 		Cache:     &fakeCache{},
-		Logf:      func(string, ...any) {},
-		Decode:    defaultDecode,
+		Logger:    discard,
+		Decode:    codec.JSON,
 		Validate:  defaultValidate,
 		RetryBase: 0,
 	})
@@ -202,7 +220,7 @@ func Test_Run_InvalidOrder_CommitsAndSkipsUpsert(t *testing.T) {
 	require.Len(t, fr.commits, 1)
 }
 
-func Test_Run_UpsertError_RetriesWithoutCommit(t *testing.T) {
+func Test_Run_UpsertError_HaltsWithoutCommit(t *testing.T) {
 	ord := validOrder()
 	msg := kafka.Message{Topic: "t", Partition: 0, Offset: 3, Key: []byte(ord.OrderUID), Value: toJSON(t, ord)}
 	fr := &fakeReader{
@@ -223,7 +241,7 @@ func Test_Run_UpsertError_RetriesWithoutCommit(t *testing.T) {
 		c.Repo = &repo.OrdersRepo{Pool: mock}
 		return c.Run(context.Background())
 	})
-	require.ErrorIs(t, err, context.Canceled)
+	require.ErrorIs(t, err, ErrHalted)
 	require.Len(t, fr.commits, 0)
 }
 
@@ -241,8 +259,8 @@ func Test_newReader_DefaultUsesKafkaNewReader(t *testing.T) {
 
 func Test_NewConsumer_NilLogger_NoPanic(t *testing.T) {
 	c := NewConsumer("b1:9092", "t", "g", &repo.OrdersRepo{}, &fakeCache{}, nil)
-	require.NotNil(t, c.Logf)
-	require.NotPanics(t, func() { c.Logf("hello %d", 1) })
+	require.NotNil(t, c.Logger)
+	require.NotPanics(t, func() { c.Logger.Info("hello", "n", 1) })
 }
 
 func (s *stubRepo) UpsertOrder(ctx context.Context, o repo.Order) error {
@@ -251,6 +269,15 @@ func (s *stubRepo) UpsertOrder(ctx context.Context, o repo.Order) error {
 	return s.err
 }
 
+func (s *stubRepo) UpsertOrders(ctx context.Context, orders []repo.Order) error {
+	for _, o := range orders {
+		if err := s.UpsertOrder(ctx, o); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func Test_handleMessage_Success_CommitsAndCaches_OneUpsert(t *testing.T) {
 	ord := validOrder()
 	msg := kafka.Message{
@@ -268,8 +295,8 @@ func Test_handleMessage_Success_CommitsAndCaches_OneUpsert(t *testing.T) {
 	c := &Consumer{
 		Repo:     sr,
 		Cache:    fc,
-		Logf:     func(string, ...any) {},
-		Decode:   defaultDecode,
+		Logger:   discard,
+		Decode:   codec.JSON,
 		Validate: defaultValidate,
 	}
 
@@ -299,8 +326,8 @@ func Test_handleMessage_CommitError_StillCaches_AndNoPanic(t *testing.T) {
 	c := &Consumer{
 		Repo:     sr,
 		Cache:    fc,
-		Logf:     func(string, ...any) {},
-		Decode:   defaultDecode,
+		Logger:   discard,
+		Decode:   codec.JSON,
 		Validate: defaultValidate,
 	}
 
@@ -328,8 +355,8 @@ func Test_handleMessage_UpsertError_NoCache_NoCommit(t *testing.T) {
 	c := &Consumer{
 		Repo:      sr,
 		Cache:     fc,
-		Logf:      func(string, ...any) {},
-		Decode:    defaultDecode,
+		Logger:    discard,
+		Decode:    codec.JSON,
 		Validate:  defaultValidate,
 		RetryBase: 0,
 	}
@@ -340,3 +367,162 @@ func Test_handleMessage_UpsertError_NoCache_NoCommit(t *testing.T) {
 	require.Equal(t, 0, fc.setCalls, "кэш не должен обновляться при ошибке Upsert")
 	require.Equal(t, 0, fr.commitCalls, "коммита быть не должно при ошибке Upsert")
 }
+
+type orderedRepo struct {
+	mu    sync.Mutex
+	seen  map[string][]int32
+	delay time.Duration
+	fail  map[string]bool
+}
+
+func (r *orderedRepo) UpsertOrder(ctx context.Context, o repo.Order) error {
+	time.Sleep(r.delay)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.fail[o.OrderUID] {
+		return errors.New("upsert-fail")
+	}
+	r.seen[o.OrderUID] = append(r.seen[o.OrderUID], o.SMID)
+	return nil
+}
+
+func (r *orderedRepo) UpsertOrders(ctx context.Context, orders []repo.Order) error {
+	for _, o := range orders {
+		if err := r.UpsertOrder(ctx, o); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type syncCache struct {
+	mu   sync.Mutex
+	keys map[string]int
+}
+
+func (c *syncCache) Set(key string, o repo.Order) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.keys[key]++
+}
+
+func Test_laneOf_StableByKey(t *testing.T) {
+	require.Equal(t, 0, laneOf(kafka.Message{Key: []byte("a")}, 1))
+
+	a := kafka.Message{Key: []byte("uid-42"), Partition: 0}
+	b := kafka.Message{Key: []byte("uid-42"), Partition: 5}
+	require.Equal(t, laneOf(a, 8), laneOf(b, 8), "один ключ — одна дорожка")
+
+	noKey := kafka.Message{Partition: 3}
+	require.Equal(t, laneOf(noKey, 8), laneOf(noKey, 8))
+	require.Less(t, laneOf(noKey, 8), 8)
+}
+
+func Test_Run_Parallel_PreservesPerKeyOrder_AndCommitsAll(t *testing.T) {
+	const keys, perKey, partitions = 5, 6, 2
+
+	var steps []step
+	offsets := map[int]int64{}
+	for i := 0; i < perKey; i++ {
+		for k := 0; k < keys; k++ {
+			ord := validOrder()
+			ord.OrderUID = fmt.Sprintf("uid-%d", k)
+			ord.SMID = int32(i)
+			p := k % partitions
+			steps = append(steps, step{msg: kafka.Message{
+				Topic: "t", Partition: p, Offset: offsets[p],
+				Key: []byte(ord.OrderUID), Value: toJSON(t, ord),
+			}})
+			offsets[p]++
+		}
+	}
+	steps = append(steps, step{err: context.Canceled})
+
+	fr := &fakeReader{steps: steps}
+	or := &orderedRepo{seen: map[string][]int32{}, delay: time.Millisecond}
+	sc := &syncCache{keys: map[string]int{}}
+
+	err := withReader(t, fr, func(c *Consumer) error {
+		c.Workers = 4
+		c.Repo = or
+		c.Cache = sc
+		return c.Run(context.Background())
+	})
+	require.ErrorIs(t, err, context.Canceled)
+
+	for k := 0; k < keys; k++ {
+		uid := fmt.Sprintf("uid-%d", k)
+		require.Equal(t, []int32{0, 1, 2, 3, 4, 5}, or.seen[uid], "порядок по ключу %s", uid)
+		require.Equal(t, perKey, sc.keys[uid])
+	}
+
+	last := map[int]int64{}
+	for _, m := range fr.commits {
+		require.Greater(t, m.Offset+1, last[m.Partition], "оффсеты не должны откатываться")
+		last[m.Partition] = m.Offset + 1
+	}
+	for p, n := range offsets {
+		require.Equal(t, n, last[p], "партиция %d закоммичена целиком", p)
+	}
+}
+
+func Test_Run_Parallel_HaltStopsAtFailedOffset(t *testing.T) {
+	mk := func(uid string, off int64) step {
+		ord := validOrder()
+		ord.OrderUID = uid
+		return step{msg: kafka.Message{Topic: "t", Offset: off, Key: []byte(uid), Value: toJSON(t, ord)}}
+	}
+	fr := &fakeReader{steps: []step{
+		mk("ok-1", 0),
+		mk("bad", 1),
+		mk("ok-2", 2),
+		{err: context.Canceled},
+	}}
+	or := &orderedRepo{seen: map[string][]int32{}, fail: map[string]bool{"bad": true}}
+
+	err := withReader(t, fr, func(c *Consumer) error {
+		c.Workers = 3
+		c.Repo = or
+		c.Cache = &syncCache{keys: map[string]int{}}
+		c.OnRetryExhausted = ExhaustHalt
+		return c.Run(context.Background())
+	})
+	require.ErrorIs(t, err, ErrHalted)
+
+	for _, m := range fr.commits {
+		require.Less(t, m.Offset, int64(1), "нельзя коммитить за упавшим сообщением")
+	}
+}
+
+func Test_prepare_LogsStructuredAttrs(t *testing.T) {
+	var buf bytes.Buffer
+	c := newBatchConsumer(&batchRepo{})
+	c.Logger = slog.New(slog.NewJSONHandler(&buf, nil))
+
+	ord := validOrder()
+	ord.TrackNumber = ""
+	msg := kafka.Message{Topic: "t", Partition: 2, Offset: 17, Value: toJSON(t, ord)}
+	_, ok, err := c.prepare(context.Background(), &fakeReader{}, msg)
+	require.False(t, ok)
+	require.NoError(t, err)
+
+	var entry struct {
+		Level    string `json:"level"`
+		Msg      string `json:"msg"`
+		OrderUID string `json:"order_uid"`
+		Err      string `json:"err"`
+		Kafka    struct {
+			Topic     string `json:"topic"`
+			Partition int    `json:"partition"`
+			Offset    int64  `json:"offset"`
+		} `json:"kafka"`
+	}
+	require.NoError(t, json.Unmarshal(bytes.SplitN(buf.Bytes(), []byte("\n"), 2)[0], &entry))
+	require.Equal(t, "WARN", entry.Level)
+	require.Equal(t, "invalid order", entry.Msg)
+	require.Equal(t, ord.OrderUID, entry.OrderUID)
+	require.Equal(t, "field track_number: empty", entry.Err)
+	require.Equal(t, "t", entry.Kafka.Topic)
+	require.Equal(t, 2, entry.Kafka.Partition)
+	require.EqualValues(t, 17, entry.Kafka.Offset)
+}
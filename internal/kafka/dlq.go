@@ -0,0 +1,118 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/mrussa/L0/internal/codec"
+	"github.com/mrussa/L0/internal/validate"
+	"github.com/segmentio/kafka-go"
+)
+
+type writer interface {
+	WriteMessages(context.Context, ...kafka.Message) error
+	Close() error
+}
+
+const (
+	stageDecode   = "decode"
+	stageValidate = "validate"
+)
+
+const (
+	hdrOrigTopic     = "dlq-original-topic"
+	hdrOrigPartition = "dlq-original-partition"
+	hdrOrigOffset    = "dlq-original-offset"
+	hdrStage         = "dlq-stage"
+	hdrError         = "dlq-error"
+	hdrFailedAt      = "dlq-failed-at"
+	hdrViolations    = "dlq-violations"
+)
+
+var newWriter = func(brokers []string, topic string) writer {
+	return &kafka.Writer{
+		Addr:                   kafka.TCP(brokers...),
+		Topic:                  topic,
+		Balancer:               &kafka.Hash{},
+		RequiredAcks:           kafka.RequireAll,
+		AllowAutoTopicCreation: true,
+	}
+}
+
+func deadLetter(msg kafka.Message, stage string, cause error) kafka.Message {
+	headers := make([]kafka.Header, 0, len(msg.Headers)+7)
+	headers = append(headers, msg.Headers...)
+	headers = append(headers,
+		kafka.Header{Key: hdrOrigTopic, Value: []byte(msg.Topic)},
+		kafka.Header{Key: hdrOrigPartition, Value: []byte(strconv.Itoa(msg.Partition))},
+		kafka.Header{Key: hdrOrigOffset, Value: []byte(strconv.FormatInt(msg.Offset, 10))},
+		kafka.Header{Key: hdrStage, Value: []byte(stage)},
+		kafka.Header{Key: hdrError, Value: []byte(cause.Error())},
+		kafka.Header{Key: hdrFailedAt, Value: []byte(time.Now().UTC().Format(time.RFC3339Nano))},
+	)
+	if b := violationsJSON(cause); b != nil {
+		headers = append(headers, kafka.Header{Key: hdrViolations, Value: b})
+	}
+	return kafka.Message{
+		Key:     msg.Key,
+		Value:   msg.Value,
+		Headers: headers,
+	}
+}
+
+func (c *Consumer) publishDLQ(ctx context.Context, msg kafka.Message, stage string, cause error) error {
+	dl := deadLetter(msg, stage, cause)
+	attempts := max(c.RetryAttempts, 1)
+	for attempt := 1; ; attempt++ {
+		err := c.dlq.WriteMessages(ctx, dl)
+		if err == nil {
+			return nil
+		}
+		c.logger().ErrorContext(ctx, "dlq publish failed", msgAttr(msg), "attempt", attempt, "attempts", attempts, "err", err)
+		if attempt >= attempts || ctx.Err() != nil {
+			return err
+		}
+		if err := sleepCtx(ctx, c.retryDelay(attempt)); err != nil {
+			return err
+		}
+	}
+}
+
+func violationsJSON(cause error) []byte {
+	var v any
+	var de *codec.DecodeError
+	var ve *validate.Error
+	switch {
+	case errors.As(cause, &de):
+		v = de.Violations
+	case errors.As(cause, &ve):
+		v = ve.Violations
+	default:
+		return nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	return b
+}
+
+func (c *Consumer) reject(ctx context.Context, r committer, msg kafka.Message, stage string, cause error) error {
+	if c.dlq != nil {
+		if err := c.publishDLQ(ctx, msg, stage, cause); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("%w at %s[%d]#%d: dlq publish failed: %w", ErrHalted, msg.Topic, msg.Partition, msg.Offset, err)
+		}
+		c.logger().WarnContext(ctx, "dead-lettered", msgAttr(msg), "dlq_topic", c.DLQTopic, "stage", stage)
+	}
+	if err := r.CommitMessages(ctx, msg); err != nil {
+		c.logger().WarnContext(ctx, "commit failed", msgAttr(msg), "err", err)
+	}
+	return nil
+}
@@ -0,0 +1,283 @@
+package kafka
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrussa/L0/internal/codec"
+	"github.com/mrussa/L0/internal/validate"
+)
+
+type fakeWriter struct {
+	msgs     []kafka.Message
+	err      error
+	failures int
+	calls    int
+	closed   bool
+}
+
+func (w *fakeWriter) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+	w.calls++
+	if w.err != nil {
+		return w.err
+	}
+	if w.failures > 0 {
+		w.failures--
+		return errors.New("leader not available")
+	}
+	w.msgs = append(w.msgs, msgs...)
+	return nil
+}
+
+func (w *fakeWriter) Close() error { w.closed = true; return nil }
+
+func headerMap(hs []kafka.Header) map[string]string {
+	m := make(map[string]string, len(hs))
+	for _, h := range hs {
+		m[h.Key] = string(h.Value)
+	}
+	return m
+}
+
+func Test_deadLetter_CopiesPayloadAndAddsHeaders(t *testing.T) {
+	msg := kafka.Message{
+		Topic:     "orders",
+		Partition: 3,
+		Offset:    42,
+		Key:       []byte("k"),
+		Value:     []byte("v"),
+		Headers:   []kafka.Header{{Key: "trace", Value: []byte("abc")}},
+	}
+
+	got := deadLetter(msg, stageValidate, errors.New("field currency: empty"))
+
+	require.Equal(t, []byte("k"), got.Key)
+	require.Equal(t, []byte("v"), got.Value)
+	require.Empty(t, got.Topic, "топик задаёт writer")
+
+	h := headerMap(got.Headers)
+	require.Equal(t, "abc", h["trace"])
+	require.Equal(t, "orders", h[hdrOrigTopic])
+	require.Equal(t, "3", h[hdrOrigPartition])
+	require.Equal(t, "42", h[hdrOrigOffset])
+	require.Equal(t, stageValidate, h[hdrStage])
+	require.Equal(t, "field currency: empty", h[hdrError])
+	require.NotEmpty(t, h[hdrFailedAt])
+	require.NotContains(t, h, hdrViolations)
+}
+
+func Test_handleMessage_StrictJSON_RecordsViolations(t *testing.T) {
+	fr := &fakeReader{}
+	fw := &fakeWriter{}
+	var logs bytes.Buffer
+	dec := &codec.JSONDecoder{Strict: true}
+	c := &Consumer{
+		DLQTopic: "orders.dlq",
+		Repo:     &stubRepo{},
+		Cache:    &fakeCache{},
+		Logger:   slog.New(slog.NewJSONHandler(&logs, nil)),
+		Decode:   dec.Decode,
+		Validate: defaultValidate,
+		dlq:      fw,
+	}
+
+	msg := kafka.Message{Topic: "t", Offset: 9, Value: []byte(`{"order_uid":"u1","trak_number":"T","payment":{"amount":"10"}}`)}
+	c.handleMessage(context.Background(), fr, msg)
+
+	require.Len(t, fw.msgs, 1)
+	h := headerMap(fw.msgs[0].Headers)
+	require.Equal(t, stageDecode, h[hdrStage])
+	require.Equal(t, "invalid payload: $.payment.amount: want integer, got string; $.trak_number: unknown field", h[hdrError])
+
+	var vs []codec.Violation
+	require.NoError(t, json.Unmarshal([]byte(h[hdrViolations]), &vs))
+	require.Equal(t, []codec.Violation{
+		{Path: "$.payment.amount", Message: "want integer, got string"},
+		{Path: "$.trak_number", Message: "unknown field"},
+	}, vs)
+
+	var entry struct {
+		Msg        string   `json:"msg"`
+		Violations []string `json:"violations"`
+	}
+	require.NoError(t, json.Unmarshal(bytes.SplitN(logs.Bytes(), []byte("\n"), 2)[0], &entry))
+	require.Equal(t, "bad payload", entry.Msg)
+	require.Equal(t, []string{"$.payment.amount: want integer, got string", "$.trak_number: unknown field"}, entry.Violations)
+	require.Equal(t, 1, fr.commitCalls)
+}
+
+func Test_handleMessage_BadJSON_PublishesToDLQ_AndCommits(t *testing.T) {
+	fr := &fakeReader{}
+	fw := &fakeWriter{}
+	c := &Consumer{
+		DLQTopic: "orders.dlq",
+		Repo:     &stubRepo{},
+		Cache:    &fakeCache{},
+		Logger:   discard,
+		Decode:   codec.JSON,
+		Validate: defaultValidate,
+		dlq:      fw,
+	}
+
+	msg := kafka.Message{Topic: "t", Partition: 1, Offset: 7, Value: []byte("not-json")}
+	c.handleMessage(context.Background(), fr, msg)
+
+	require.Len(t, fw.msgs, 1)
+	h := headerMap(fw.msgs[0].Headers)
+	require.Equal(t, stageDecode, h[hdrStage])
+	require.Equal(t, "7", h[hdrOrigOffset])
+	require.NotEmpty(t, h[hdrError])
+	require.Equal(t, 1, fr.commitCalls)
+}
+
+func Test_handleMessage_Invalid_PublishesToDLQ_WithValidateStage(t *testing.T) {
+	ord := validOrder()
+	ord.TrackNumber = ""
+	fr := &fakeReader{}
+	fw := &fakeWriter{}
+	sr := &stubRepo{}
+	c := &Consumer{
+		Repo:     sr,
+		Cache:    &fakeCache{},
+		Logger:   discard,
+		Decode:   codec.JSON,
+		Validate: defaultValidate,
+		dlq:      fw,
+	}
+
+	c.handleMessage(context.Background(), fr, kafka.Message{Topic: "t", Offset: 8, Value: toJSON(t, ord)})
+
+	require.Len(t, fw.msgs, 1)
+	h := headerMap(fw.msgs[0].Headers)
+	require.Equal(t, stageValidate, h[hdrStage])
+	require.Equal(t, "field track_number: empty", h[hdrError])
+	require.Equal(t, 0, sr.calls)
+	require.Equal(t, 1, fr.commitCalls)
+}
+
+func Test_handleMessage_Invalid_RecordsRuleViolations(t *testing.T) {
+	ord := validOrder()
+	ord.Payment.Currency = "XYZ"
+	ord.Delivery.Phone = "8-900"
+	fr := &fakeReader{}
+	fw := &fakeWriter{}
+	c := &Consumer{
+		Repo:     &stubRepo{},
+		Cache:    &fakeCache{},
+		Logger:   discard,
+		Decode:   codec.JSON,
+		Validate: validate.MustParse("required,currency,phone").Validate,
+		dlq:      fw,
+	}
+
+	c.handleMessage(context.Background(), fr, kafka.Message{Topic: "t", Offset: 8, Value: toJSON(t, ord)})
+
+	require.Len(t, fw.msgs, 1)
+	h := headerMap(fw.msgs[0].Headers)
+	require.Equal(t, stageValidate, h[hdrStage])
+
+	var vs []validate.Violation
+	require.NoError(t, json.Unmarshal([]byte(h[hdrViolations]), &vs))
+	require.Len(t, vs, 2, "все нарушения в одном сообщении")
+	require.Equal(t, "currency", vs[0].Rule)
+	require.Equal(t, "phone", vs[1].Field)
+	require.Equal(t, 1, fr.commitCalls)
+}
+
+func Test_handleMessage_DLQWriteError_HaltsWithoutCommit(t *testing.T) {
+	fr := &fakeReader{}
+	fw := &fakeWriter{err: errors.New("broker down")}
+	c := &Consumer{
+		Repo:          &stubRepo{},
+		Cache:         &fakeCache{},
+		Logger:        discard,
+		Decode:        codec.JSON,
+		Validate:      defaultValidate,
+		RetryAttempts: 3,
+		dlq:           fw,
+	}
+
+	err := c.handleMessage(context.Background(), fr, kafka.Message{Topic: "t", Offset: 9, Value: []byte("{")})
+
+	require.ErrorIs(t, err, ErrHalted, "дорожка должна остановиться, иначе коммиты партиции встанут")
+	require.Equal(t, 3, fw.calls)
+	require.Empty(t, fw.msgs)
+	require.Equal(t, 0, fr.commitCalls, "сообщение не должно потеряться, если DLQ недоступен")
+}
+
+func Test_handleMessage_DLQWriteError_RetriesThenCommits(t *testing.T) {
+	fr := &fakeReader{}
+	fw := &fakeWriter{failures: 2}
+	c := &Consumer{
+		Repo:          &stubRepo{},
+		Cache:         &fakeCache{},
+		Logger:        discard,
+		Decode:        codec.JSON,
+		Validate:      defaultValidate,
+		RetryAttempts: 3,
+		dlq:           fw,
+	}
+
+	err := c.handleMessage(context.Background(), fr, kafka.Message{Topic: "t", Offset: 9, Value: []byte("{")})
+
+	require.NoError(t, err)
+	require.Equal(t, 3, fw.calls)
+	require.Len(t, fw.msgs, 1)
+	require.Equal(t, 1, fr.commitCalls)
+}
+
+func Test_handleBatch_DLQWriteError_Halts(t *testing.T) {
+	fr := &fakeReader{}
+	br := &batchRepo{}
+	c := newBatchConsumer(br)
+	c.dlq = &fakeWriter{err: errors.New("broker down")}
+
+	msgs := []kafka.Message{
+		{Topic: "t", Offset: 1, Value: []byte("{")},
+		{Topic: "t", Offset: 2, Value: toJSON(t, validOrder())},
+	}
+	err := c.handleBatch(context.Background(), fr, msgs)
+
+	require.ErrorIs(t, err, ErrHalted)
+	require.Equal(t, 0, fr.commitCalls)
+}
+
+func Test_Run_WithDLQTopic_OpensAndClosesWriter(t *testing.T) {
+	fw := &fakeWriter{}
+	orig := newWriter
+	var gotTopic string
+	newWriter = func(brokers []string, topic string) writer {
+		gotTopic = topic
+		return fw
+	}
+	defer func() { newWriter = orig }()
+
+	fr := &fakeReader{
+		steps: []step{
+			{msg: kafka.Message{Topic: "t", Offset: 1, Value: []byte("not-json")}},
+			{err: context.Canceled},
+		},
+	}
+	err := withReader(t, fr, func(c *Consumer) error {
+		c.DLQTopic = "t.dlq"
+		return c.Run(context.Background())
+	})
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, "t.dlq", gotTopic)
+	require.Len(t, fw.msgs, 1)
+	require.True(t, fw.closed)
+	require.Len(t, fr.commits, 1)
+}
+
+func Test_newWriter_Default(t *testing.T) {
+	w := newWriter([]string{"127.0.0.1:1"}, "t.dlq")
+	require.NotNil(t, w)
+	require.NoError(t, w.Close())
+}
@@ -0,0 +1,35 @@
+package kafka
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mrussa/L0/internal/codec"
+	"github.com/segmentio/kafka-go"
+)
+
+const headerContentType = "content-type"
+
+func (c *Consumer) decoderFor(msg kafka.Message) (Decoder, error) {
+	ct := headerValue(msg.Headers, headerContentType)
+	if ct == "" {
+		return c.Decode, nil
+	}
+	f, err := codec.FormatOf(ct)
+	if err != nil {
+		return nil, fmt.Errorf("content-type %q: %w", ct, err)
+	}
+	if d, ok := c.Decoders[f]; ok {
+		return d, nil
+	}
+	return nil, fmt.Errorf("content-type %q: no %s decoder configured", ct, f)
+}
+
+func headerValue(headers []kafka.Header, key string) string {
+	for _, h := range headers {
+		if strings.EqualFold(h.Key, key) {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,64 @@
+package kafka
+
+import (
+	"context"
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrussa/L0/internal/codec"
+	"github.com/mrussa/L0/internal/repo"
+)
+
+func Test_decoderFor(t *testing.T) {
+	c := NewConsumer("", "t", "g", nil, &fakeCache{}, discard)
+	c.Decoders[codec.FormatProtobuf] = codec.Protobuf
+
+	ord := validOrder()
+	pb, err := codec.MarshalProtobuf(ord)
+	require.NoError(t, err)
+
+	msg := kafka.Message{Value: toJSON(t, ord)}
+	dec, err := c.decoderFor(msg)
+	require.NoError(t, err)
+	require.NotNil(t, dec)
+
+	msg = kafka.Message{Value: pb, Headers: []kafka.Header{{Key: "Content-Type", Value: []byte("application/x-protobuf")}}}
+	dec, err = c.decoderFor(msg)
+	require.NoError(t, err)
+	var got repo.Order
+	require.NoError(t, dec(msg.Value, &got))
+	require.Equal(t, ord.OrderUID, got.OrderUID)
+
+	msg.Headers = []kafka.Header{{Key: headerContentType, Value: []byte("avro/binary")}}
+	_, err = c.decoderFor(msg)
+	require.EqualError(t, err, `content-type "avro/binary": no avro decoder configured`)
+
+	msg.Headers = []kafka.Header{{Key: headerContentType, Value: []byte("text/xml")}}
+	_, err = c.decoderFor(msg)
+	require.ErrorIs(t, err, codec.ErrUnknownFormat)
+}
+
+func Test_prepare_PicksDecoderByContentType(t *testing.T) {
+	c := newBatchConsumer(&batchRepo{})
+	c.Decoders = map[codec.Format]Decoder{codec.FormatJSON: codec.JSON, codec.FormatProtobuf: codec.Protobuf}
+
+	ord := validOrder()
+	pb, err := codec.MarshalProtobuf(ord)
+	require.NoError(t, err)
+
+	msg := kafka.Message{Topic: "t", Value: pb, Headers: []kafka.Header{{Key: headerContentType, Value: []byte("application/x-protobuf")}}}
+	got, ok, err := c.prepare(context.Background(), &fakeReader{}, msg)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, ord.OrderUID, got.OrderUID)
+	require.True(t, ord.DateCreated.Equal(got.DateCreated))
+
+	fr := &fakeReader{}
+	msg.Headers = []kafka.Header{{Key: headerContentType, Value: []byte("application/avro")}}
+	_, ok, err = c.prepare(context.Background(), fr, msg)
+	require.NoError(t, err)
+	require.False(t, ok, "без avro-декодера сообщение отклоняется")
+	require.Len(t, fr.commits, 1)
+}
@@ -0,0 +1,67 @@
+package kafka
+
+import (
+	"sync"
+	"time"
+)
+
+type Health struct {
+	Running    bool
+	Started    time.Time
+	LastFetch  time.Time
+	LastCommit time.Time
+	Err        error
+}
+
+type healthState struct {
+	mu sync.Mutex
+	h  Health
+}
+
+func (s *healthState) start(now time.Time) {
+	s.mu.Lock()
+	s.h = Health{Running: true, Started: now}
+	s.mu.Unlock()
+}
+
+func (s *healthState) fetched(now time.Time) {
+	s.mu.Lock()
+	s.h.LastFetch = now
+	s.mu.Unlock()
+}
+
+func (s *healthState) committed(now time.Time) {
+	s.mu.Lock()
+	s.h.LastCommit = now
+	s.mu.Unlock()
+}
+
+func (s *healthState) stop(err error) {
+	s.mu.Lock()
+	s.h.Running = false
+	s.h.Err = err
+	s.mu.Unlock()
+}
+
+func (s *healthState) get() Health {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.h
+}
+
+func (c *Consumer) Health() Health {
+	return c.health.get()
+}
+
+func (h Health) addDetail(detail map[string]any) {
+	if !h.Started.IsZero() {
+		detail["started"] = h.Started
+	}
+	if !h.LastFetch.IsZero() {
+		detail["last_fetch"] = h.LastFetch
+		detail["last_fetch_age_ms"] = time.Since(h.LastFetch).Milliseconds()
+	}
+	if !h.LastCommit.IsZero() {
+		detail["last_commit"] = h.LastCommit
+	}
+}
@@ -0,0 +1,57 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Health_NotStarted(t *testing.T) {
+	c := &Consumer{}
+	require.False(t, c.Health().Running)
+
+	detail := map[string]any{}
+	c.Health().addDetail(detail)
+	require.Empty(t, detail)
+}
+
+func Test_Health_TracksFetchAndExit(t *testing.T) {
+	fr := &fakeReader{
+		steps: []step{
+			{msg: kafka.Message{Topic: "t", Partition: 0, Offset: 1, Value: []byte("not-json")}},
+			{err: errors.New("boom")},
+		},
+	}
+	var c *Consumer
+	err := withReader(t, fr, func(cc *Consumer) error {
+		c = cc
+		return cc.Run(context.Background())
+	})
+	require.EqualError(t, err, "boom")
+
+	h := c.Health()
+	require.False(t, h.Running)
+	require.False(t, h.Started.IsZero())
+	require.False(t, h.LastFetch.IsZero(), "время последнего fetch должно быть записано")
+	require.False(t, h.LastCommit.IsZero(), "битое сообщение закоммичено — это прогресс")
+	require.EqualError(t, h.Err, "boom")
+
+	detail := map[string]any{}
+	h.addDetail(detail)
+	require.Contains(t, detail, "started")
+	require.Contains(t, detail, "last_fetch_age_ms")
+	require.Contains(t, detail, "last_commit")
+}
+
+func Test_Health_RunningWhileFetching(t *testing.T) {
+	c := &Consumer{}
+	c.health.start(time.Now())
+
+	h := c.Health()
+	require.True(t, h.Running)
+	require.True(t, h.LastFetch.IsZero())
+}
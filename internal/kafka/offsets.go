@@ -0,0 +1,114 @@
+package kafka
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+const commitTimeout = 5 * time.Second
+
+type committer interface {
+	CommitMessages(context.Context, ...kafka.Message) error
+}
+
+type partitionKey struct {
+	topic     string
+	partition int
+}
+
+type partitionOffsets struct {
+	mu      sync.Mutex
+	pending []int64
+	done    map[int64]kafka.Message
+}
+
+// offsetTracker commits a partition only up to the highest offset below
+// which every fetched message has been handled, so lanes may finish out of order.
+type offsetTracker struct {
+	r        committer
+	onCommit func()
+
+	mu    sync.Mutex
+	parts map[partitionKey]*partitionOffsets
+}
+
+func newOffsetTracker(r committer) *offsetTracker {
+	return &offsetTracker{
+		r:     r,
+		parts: make(map[partitionKey]*partitionOffsets),
+	}
+}
+
+func (t *offsetTracker) partition(topic string, partition int) *partitionOffsets {
+	k := partitionKey{topic: topic, partition: partition}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	p, ok := t.parts[k]
+	if !ok {
+		p = &partitionOffsets{done: make(map[int64]kafka.Message)}
+		t.parts[k] = p
+	}
+	return p
+}
+
+func (t *offsetTracker) Track(msg kafka.Message) {
+	p := t.partition(msg.Topic, msg.Partition)
+	p.mu.Lock()
+	p.pending = append(p.pending, msg.Offset)
+	p.mu.Unlock()
+}
+
+func (t *offsetTracker) CommitMessages(ctx context.Context, msgs ...kafka.Message) error {
+	byPart := make(map[partitionKey][]kafka.Message, 1)
+	for _, m := range msgs {
+		k := partitionKey{topic: m.Topic, partition: m.Partition}
+		byPart[k] = append(byPart[k], m)
+	}
+
+	var firstErr error
+	for k, ms := range byPart {
+		if err := t.ack(ctx, t.partition(k.topic, k.partition), ms); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (t *offsetTracker) ack(ctx context.Context, p *partitionOffsets, msgs []kafka.Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, m := range msgs {
+		p.done[m.Offset] = m
+	}
+
+	var (
+		last  kafka.Message
+		ready bool
+	)
+	for len(p.pending) > 0 {
+		m, ok := p.done[p.pending[0]]
+		if !ok {
+			break
+		}
+		delete(p.done, p.pending[0])
+		p.pending = p.pending[1:]
+		last, ready = m, true
+	}
+	if !ready {
+		return nil
+	}
+
+	ctxC, cancel := context.WithTimeout(context.WithoutCancel(ctx), commitTimeout)
+	defer cancel()
+	if err := t.r.CommitMessages(ctxC, last); err != nil {
+		return err
+	}
+	if t.onCommit != nil {
+		t.onCommit()
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package kafka
+
+import (
+	"context"
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/require"
+)
+
+func msgAt(p int, off int64) kafka.Message {
+	return kafka.Message{Topic: "t", Partition: p, Offset: off}
+}
+
+func committedOffsets(fr *fakeReader) []int64 {
+	out := make([]int64, 0, len(fr.commits))
+	for _, m := range fr.commits {
+		out = append(out, m.Offset)
+	}
+	return out
+}
+
+func Test_offsetTracker_InOrder(t *testing.T) {
+	fr := &fakeReader{}
+	tr := newOffsetTracker(fr)
+	for i := int64(0); i < 3; i++ {
+		tr.Track(msgAt(0, i))
+	}
+	for i := int64(0); i < 3; i++ {
+		require.NoError(t, tr.CommitMessages(context.Background(), msgAt(0, i)))
+	}
+	require.Equal(t, []int64{0, 1, 2}, committedOffsets(fr))
+}
+
+func Test_offsetTracker_OutOfOrder_WaitsForGap(t *testing.T) {
+	fr := &fakeReader{}
+	tr := newOffsetTracker(fr)
+	for i := int64(10); i < 14; i++ {
+		tr.Track(msgAt(0, i))
+	}
+
+	require.NoError(t, tr.CommitMessages(context.Background(), msgAt(0, 12)))
+	require.NoError(t, tr.CommitMessages(context.Background(), msgAt(0, 11)))
+	require.Empty(t, fr.commits, "10 ещё не обработан")
+
+	require.NoError(t, tr.CommitMessages(context.Background(), msgAt(0, 10)))
+	require.Equal(t, []int64{12}, committedOffsets(fr))
+
+	require.NoError(t, tr.CommitMessages(context.Background(), msgAt(0, 13)))
+	require.Equal(t, []int64{12, 13}, committedOffsets(fr))
+}
+
+func Test_offsetTracker_PartitionsIndependent(t *testing.T) {
+	fr := &fakeReader{}
+	tr := newOffsetTracker(fr)
+	tr.Track(msgAt(0, 0))
+	tr.Track(msgAt(0, 1))
+	tr.Track(msgAt(1, 0))
+
+	require.NoError(t, tr.CommitMessages(context.Background(), msgAt(0, 1), msgAt(1, 0)))
+	require.Len(t, fr.commits, 1)
+	require.Equal(t, 1, fr.commits[0].Partition)
+}
+
+func Test_offsetTracker_CanceledContext_StillCommits(t *testing.T) {
+	fr := &fakeReader{}
+	tr := newOffsetTracker(fr)
+	tr.Track(msgAt(0, 0))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.NoError(t, tr.CommitMessages(ctx, msgAt(0, 0)))
+	require.Len(t, fr.commits, 1)
+}
+
+func Test_offsetTracker_CommitError(t *testing.T) {
+	fr := &fakeReader{commitErrAt: 1}
+	tr := newOffsetTracker(fr)
+	tr.Track(msgAt(0, 0))
+	tr.Track(msgAt(0, 1))
+
+	require.EqualError(t, tr.CommitMessages(context.Background(), msgAt(0, 0)), "commit-err")
+	require.NoError(t, tr.CommitMessages(context.Background(), msgAt(0, 1)))
+	require.Equal(t, []int64{0, 1}, committedOffsets(fr))
+}
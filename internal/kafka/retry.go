@@ -0,0 +1,92 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/mrussa/L0/internal/repo"
+	"github.com/segmentio/kafka-go"
+)
+
+type ExhaustPolicy string
+
+const (
+	ExhaustHalt ExhaustPolicy = "halt"
+	ExhaustDLQ  ExhaustPolicy = "dlq"
+)
+
+const stageUpsert = "upsert"
+
+var ErrHalted = errors.New("consumer halted")
+
+func (c *Consumer) retryDelay(attempt int) time.Duration {
+	if c.RetryBase <= 0 {
+		return 0
+	}
+	ceiling := c.RetryMax
+	if ceiling <= 0 {
+		ceiling = retryMax
+	}
+	d := min(c.RetryBase, ceiling)
+	for i := 1; i < attempt && d < ceiling; i++ {
+		d = min(d*2, ceiling)
+	}
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+func (c *Consumer) upsertWithRetry(ctx context.Context, ord repo.Order) error {
+	attempts := max(c.RetryAttempts, 1)
+	for attempt := 1; ; attempt++ {
+		start := time.Now()
+		err := c.Repo.UpsertOrder(ctx, ord)
+		c.metrics().ObserveUpsert(false, time.Since(start))
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		permanent := repo.IsPermanent(err)
+		c.logger().WarnContext(ctx, "upsert failed", "order_uid", ord.OrderUID, "attempt", attempt, "attempts", attempts, "permanent", permanent, "err", err)
+		if permanent || attempt >= attempts {
+			return err
+		}
+		if err := sleepCtx(ctx, c.retryDelay(attempt)); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *Consumer) retriesExhausted(ctx context.Context, r committer, msg kafka.Message, ord repo.Order, cause error) error {
+	if repo.IsPermanent(cause) {
+		c.logger().ErrorContext(ctx, "order rejected by database", msgAttr(msg), "order_uid", ord.OrderUID, "err", cause)
+		c.metrics().Messages("invalid", 1)
+		return c.reject(ctx, r, msg, stageUpsert, cause)
+	}
+	if c.OnRetryExhausted == ExhaustDLQ {
+		if c.dlq == nil {
+			c.logger().ErrorContext(ctx, "retries exhausted but no DLQ topic configured, halting", "order_uid", ord.OrderUID)
+		} else if err := c.reject(ctx, r, msg, stageUpsert, cause); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w at %s[%d]#%d (%s): %v", ErrHalted, msg.Topic, msg.Partition, msg.Offset, ord.OrderUID, cause)
+}
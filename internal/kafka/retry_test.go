@@ -0,0 +1,209 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/mrussa/L0/internal/codec"
+	"github.com/mrussa/L0/internal/repo"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/require"
+)
+
+type flakyRepo struct {
+	failures int
+	calls    int
+}
+
+func (f *flakyRepo) UpsertOrder(ctx context.Context, o repo.Order) error {
+	f.calls++
+	if f.calls <= f.failures {
+		return errors.New("db unavailable")
+	}
+	return nil
+}
+
+func (f *flakyRepo) UpsertOrders(ctx context.Context, orders []repo.Order) error {
+	return errors.New("batch not supported")
+}
+
+type brokenRepo struct {
+	err   error
+	calls int
+}
+
+func (b *brokenRepo) UpsertOrder(ctx context.Context, o repo.Order) error {
+	b.calls++
+	return b.err
+}
+
+func (b *brokenRepo) UpsertOrders(ctx context.Context, orders []repo.Order) error {
+	return b.err
+}
+
+func newRetryConsumer(r orderStore) *Consumer {
+	return &Consumer{
+		Repo:          r,
+		Cache:         &fakeCache{},
+		Logger:        discard,
+		Decode:        codec.JSON,
+		Validate:      defaultValidate,
+		RetryAttempts: 3,
+	}
+}
+
+func Test_retryDelay_ExponentialWithCap(t *testing.T) {
+	c := &Consumer{RetryBase: 100 * time.Millisecond, RetryMax: 350 * time.Millisecond}
+
+	within := func(d, lo, hi time.Duration) {
+		t.Helper()
+		require.GreaterOrEqual(t, d, lo)
+		require.LessOrEqual(t, d, hi)
+	}
+	within(c.retryDelay(1), 50*time.Millisecond, 100*time.Millisecond)
+	within(c.retryDelay(2), 100*time.Millisecond, 200*time.Millisecond)
+	within(c.retryDelay(3), 175*time.Millisecond, 350*time.Millisecond)
+	within(c.retryDelay(30), 175*time.Millisecond, 350*time.Millisecond)
+
+	require.Zero(t, (&Consumer{}).retryDelay(5))
+
+	uncapped := &Consumer{RetryBase: time.Second}
+	for _, attempt := range []int{1, 35, 64, 1000} {
+		within(uncapped.retryDelay(attempt), 0, retryMax)
+	}
+	within(uncapped.retryDelay(1000), retryMax/2, retryMax)
+}
+
+func Test_sleepCtx(t *testing.T) {
+	require.NoError(t, sleepCtx(context.Background(), 0))
+	require.NoError(t, sleepCtx(context.Background(), time.Millisecond))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	start := time.Now()
+	require.ErrorIs(t, sleepCtx(ctx, time.Hour), context.Canceled)
+	require.Less(t, time.Since(start), time.Second)
+	require.ErrorIs(t, sleepCtx(ctx, 0), context.Canceled)
+}
+
+func Test_handleMessage_RetriesUntilSuccess(t *testing.T) {
+	ord := validOrder()
+	fr := &fakeReader{}
+	fl := &flakyRepo{failures: 2}
+	c := newRetryConsumer(fl)
+
+	err := c.handleMessage(context.Background(), fr, kafka.Message{Topic: "t", Offset: 1, Value: toJSON(t, ord)})
+	require.NoError(t, err)
+	require.Equal(t, 3, fl.calls)
+	require.Equal(t, 1, c.Cache.(*fakeCache).setCalls)
+	require.Equal(t, 1, fr.commitCalls)
+}
+
+func Test_handleMessage_RetriesExhausted_Halt(t *testing.T) {
+	ord := validOrder()
+	fr := &fakeReader{}
+	fl := &flakyRepo{failures: 10}
+	c := newRetryConsumer(fl)
+	c.OnRetryExhausted = ExhaustHalt
+
+	err := c.handleMessage(context.Background(), fr, kafka.Message{Topic: "t", Partition: 2, Offset: 5, Value: toJSON(t, ord)})
+	require.ErrorIs(t, err, ErrHalted)
+	require.ErrorContains(t, err, "t[2]#5")
+	require.Equal(t, 3, fl.calls)
+	require.Equal(t, 0, fr.commitCalls)
+}
+
+func Test_handleMessage_RetriesExhausted_DLQ(t *testing.T) {
+	ord := validOrder()
+	fr := &fakeReader{}
+	fw := &fakeWriter{}
+	c := newRetryConsumer(&flakyRepo{failures: 10})
+	c.OnRetryExhausted = ExhaustDLQ
+	c.dlq = fw
+
+	err := c.handleMessage(context.Background(), fr, kafka.Message{Topic: "t", Offset: 6, Value: toJSON(t, ord)})
+	require.NoError(t, err)
+	require.Len(t, fw.msgs, 1)
+	require.Equal(t, stageUpsert, headerMap(fw.msgs[0].Headers)[hdrStage])
+	require.Equal(t, "db unavailable", headerMap(fw.msgs[0].Headers)[hdrError])
+	require.Equal(t, 1, fr.commitCalls)
+	require.Equal(t, 0, c.Cache.(*fakeCache).setCalls)
+}
+
+func Test_handleMessage_RetriesExhausted_DLQPolicyWithoutTopic_Halts(t *testing.T) {
+	fr := &fakeReader{}
+	c := newRetryConsumer(&flakyRepo{failures: 10})
+	c.OnRetryExhausted = ExhaustDLQ
+
+	err := c.handleMessage(context.Background(), fr, kafka.Message{Topic: "t", Value: toJSON(t, validOrder())})
+	require.ErrorIs(t, err, ErrHalted)
+	require.Equal(t, 0, fr.commitCalls)
+}
+
+func Test_handleMessage_RetriesExhausted_DLQWriteError_Halts(t *testing.T) {
+	fr := &fakeReader{}
+	c := newRetryConsumer(&flakyRepo{failures: 10})
+	c.OnRetryExhausted = ExhaustDLQ
+	c.dlq = &fakeWriter{err: errors.New("broker down")}
+
+	err := c.handleMessage(context.Background(), fr, kafka.Message{Topic: "t", Value: toJSON(t, validOrder())})
+	require.ErrorIs(t, err, ErrHalted)
+	require.Equal(t, 0, fr.commitCalls)
+}
+
+func Test_handleMessage_ShutdownDuringBackoff_Aborts(t *testing.T) {
+	fr := &fakeReader{}
+	fl := &flakyRepo{failures: 10}
+	c := newRetryConsumer(fl)
+	c.RetryAttempts = 100
+	c.RetryBase = time.Hour
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	start := time.Now()
+	err := c.handleMessage(ctx, fr, kafka.Message{Topic: "t", Value: toJSON(t, validOrder())})
+	require.ErrorIs(t, err, context.Canceled)
+	require.Less(t, time.Since(start), 5*time.Second)
+	require.Equal(t, 1, fl.calls)
+	require.Equal(t, 0, fr.commitCalls)
+}
+
+func Test_handleMessage_PermanentUpsertError_SkipsWithoutRetry(t *testing.T) {
+	for _, cause := range []error{
+		fmt.Errorf("exec: %w", &pgconn.PgError{Code: "22021", Message: "invalid byte sequence"}),
+		fmt.Errorf("%w: negative amount", repo.ErrInconsistent),
+	} {
+		fr := &fakeReader{}
+		br := &brokenRepo{err: cause}
+		c := newRetryConsumer(br)
+		c.RetryAttempts = 5
+
+		err := c.handleMessage(context.Background(), fr, kafka.Message{Topic: "t", Offset: 3, Value: toJSON(t, validOrder())})
+		require.NoError(t, err, "halt не должен останавливать consumer на заведомо битом заказе")
+		require.Equal(t, 1, br.calls, "постоянную ошибку не повторяем")
+		require.Equal(t, 1, fr.commitCalls)
+	}
+}
+
+func Test_handleBatch_PermanentUpsertError_GoesToDLQ(t *testing.T) {
+	fr := &fakeReader{}
+	fw := &fakeWriter{}
+	br := &brokenRepo{err: &pgconn.PgError{Code: "23505"}}
+	c := newRetryConsumer(br)
+	c.dlq = fw
+
+	msgs := []kafka.Message{
+		{Topic: "t", Offset: 1, Value: toJSON(t, validOrder())},
+		{Topic: "t", Offset: 2, Value: toJSON(t, validOrder())},
+	}
+	require.NoError(t, c.handleBatch(context.Background(), fr, msgs))
+	require.Equal(t, 2, br.calls)
+	require.Len(t, fw.msgs, 2)
+	require.Equal(t, stageUpsert, headerMap(fw.msgs[0].Headers)[hdrStage])
+	require.Len(t, fr.commits, 2)
+}
@@ -0,0 +1,200 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+type State string
+
+const (
+	StateStarting   State = "starting"
+	StateRunning    State = "running"
+	StateRestarting State = "restarting"
+	StateFailed     State = "failed"
+	StateStopped    State = "stopped"
+)
+
+const (
+	restartBase = time.Second
+	restartMax  = time.Minute
+)
+
+var ErrTooManyFailures = errors.New("consumer failed too many times")
+
+type SupervisorMetrics interface {
+	SetConsumerState(state string)
+	ConsumerRestarted()
+}
+
+type nopSupervisorMetrics struct{}
+
+func (nopSupervisorMetrics) SetConsumerState(string) {}
+func (nopSupervisorMetrics) ConsumerRestarted()      {}
+
+type runner interface {
+	Run(ctx context.Context) error
+	Health() Health
+}
+
+type SupervisorStatus struct {
+	State               State
+	Restarts            int
+	ConsecutiveFailures int
+	LastError           error
+	LastFailure         time.Time
+	NextRestart         time.Time
+}
+
+type Supervisor struct {
+	Consumer runner
+	Logger   *slog.Logger
+	Metrics  SupervisorMetrics
+
+	RestartBase time.Duration
+	RestartMax  time.Duration
+	MaxFailures int
+
+	mu     sync.Mutex
+	status SupervisorStatus
+}
+
+func NewSupervisor(c *Consumer, log *slog.Logger) *Supervisor {
+	if log == nil {
+		log = slog.New(slog.DiscardHandler)
+	}
+	return &Supervisor{
+		Consumer:    c,
+		Logger:      log,
+		Metrics:     nopSupervisorMetrics{},
+		RestartBase: restartBase,
+		RestartMax:  restartMax,
+		status:      SupervisorStatus{State: StateStarting},
+	}
+}
+
+func (s *Supervisor) Run(ctx context.Context) error {
+	for {
+		s.setState(StateRunning, func(st *SupervisorStatus) { st.NextRestart = time.Time{} })
+		err := s.Consumer.Run(ctx)
+		if ctx.Err() != nil {
+			s.setState(StateStopped, nil)
+			return ctx.Err()
+		}
+		if err == nil {
+			err = errors.New("consumer returned without error")
+		}
+
+		// A halted message is fetched again after every restart, so only
+		// committed offsets count as progress.
+		progressed := !s.Consumer.Health().LastCommit.IsZero()
+		var failures int
+		s.update(func(st *SupervisorStatus) {
+			if progressed {
+				st.ConsecutiveFailures = 0
+			}
+			st.ConsecutiveFailures++
+			st.LastError = err
+			st.LastFailure = time.Now()
+			failures = st.ConsecutiveFailures
+		})
+
+		if s.MaxFailures > 0 && failures >= s.MaxFailures {
+			s.setState(StateFailed, nil)
+			s.logger().ErrorContext(ctx, "consumer failed, giving up", "failures", failures, "err", err)
+			return fmt.Errorf("%w (%d in a row): %w", ErrTooManyFailures, failures, err)
+		}
+
+		delay := s.restartDelay(failures)
+		s.setState(StateRestarting, func(st *SupervisorStatus) { st.NextRestart = time.Now().Add(delay) })
+		s.logger().ErrorContext(ctx, "consumer failed, restarting", "failures", failures, "backoff", delay, "err", err)
+		if err := sleepCtx(ctx, delay); err != nil {
+			s.setState(StateStopped, nil)
+			return err
+		}
+		s.update(func(st *SupervisorStatus) { st.Restarts++ })
+		s.metrics().ConsumerRestarted()
+	}
+}
+
+func (s *Supervisor) Status() SupervisorStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.status
+}
+
+func (s *Supervisor) Ready() (map[string]any, error) {
+	st := s.Status()
+	detail := map[string]any{
+		"state":                string(st.State),
+		"restarts":             st.Restarts,
+		"consecutive_failures": st.ConsecutiveFailures,
+	}
+	if st.LastError != nil {
+		detail["last_error"] = st.LastError.Error()
+		detail["last_failure"] = st.LastFailure
+	}
+	if !st.NextRestart.IsZero() {
+		detail["next_restart"] = st.NextRestart
+	}
+
+	s.Consumer.Health().addDetail(detail)
+
+	switch {
+	case st.State == StateRunning:
+		return detail, nil
+	case st.LastError != nil:
+		return detail, fmt.Errorf("consumer %s: %w", st.State, st.LastError)
+	default:
+		return detail, fmt.Errorf("consumer %s", st.State)
+	}
+}
+
+func (s *Supervisor) restartDelay(failures int) time.Duration {
+	if s.RestartBase <= 0 {
+		return 0
+	}
+	ceiling := s.RestartMax
+	if ceiling <= 0 {
+		ceiling = restartMax
+	}
+	d := min(s.RestartBase, ceiling)
+	for i := 1; i < failures && d < ceiling; i++ {
+		d = min(d*2, ceiling)
+	}
+	return d
+}
+
+func (s *Supervisor) setState(state State, fn func(*SupervisorStatus)) {
+	s.update(func(st *SupervisorStatus) {
+		st.State = state
+		if fn != nil {
+			fn(st)
+		}
+	})
+	s.metrics().SetConsumerState(string(state))
+}
+
+func (s *Supervisor) update(fn func(*SupervisorStatus)) {
+	s.mu.Lock()
+	fn(&s.status)
+	s.mu.Unlock()
+}
+
+func (s *Supervisor) metrics() SupervisorMetrics {
+	if s.Metrics == nil {
+		return nopSupervisorMetrics{}
+	}
+	return s.Metrics
+}
+
+func (s *Supervisor) logger() *slog.Logger {
+	if s.Logger == nil {
+		return slog.New(slog.DiscardHandler)
+	}
+	return s.Logger
+}
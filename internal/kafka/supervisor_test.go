@@ -0,0 +1,247 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/require"
+)
+
+type runResult struct {
+	err       error
+	fetched   bool
+	committed bool
+}
+
+type fakeRunner struct {
+	mu      sync.Mutex
+	results []runResult
+	calls   int
+	health  Health
+	onRun   func(call int)
+}
+
+func (f *fakeRunner) Run(ctx context.Context) error {
+	f.mu.Lock()
+	f.calls++
+	call := f.calls
+	f.health = Health{Running: true, Started: time.Now()}
+	f.mu.Unlock()
+
+	if f.onRun != nil {
+		f.onRun(call)
+	}
+	if call > len(f.results) {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	res := f.results[call-1]
+
+	f.mu.Lock()
+	f.health.Running = false
+	if res.fetched || res.committed {
+		f.health.LastFetch = time.Now()
+	}
+	if res.committed {
+		f.health.LastCommit = time.Now()
+	}
+	f.mu.Unlock()
+	return res.err
+}
+
+func (f *fakeRunner) Health() Health {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.health
+}
+
+type stateMetrics struct {
+	mu       sync.Mutex
+	states   []string
+	restarts int
+}
+
+func (m *stateMetrics) SetConsumerState(state string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.states = append(m.states, state)
+}
+
+func (m *stateMetrics) ConsumerRestarted() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.restarts++
+}
+
+func newTestSupervisor(r runner) *Supervisor {
+	return &Supervisor{Consumer: r, Logger: discard, RestartBase: time.Millisecond, RestartMax: 4 * time.Millisecond}
+}
+
+func Test_NewSupervisor_Defaults(t *testing.T) {
+	c := &Consumer{}
+	s := NewSupervisor(c, nil)
+	require.Same(t, c, s.Consumer)
+	require.NotNil(t, s.Logger)
+	require.Equal(t, nopSupervisorMetrics{}, s.Metrics)
+	require.Equal(t, restartBase, s.RestartBase)
+	require.Equal(t, restartMax, s.RestartMax)
+	require.Zero(t, s.MaxFailures)
+	require.Equal(t, StateStarting, s.Status().State)
+}
+
+func Test_Supervisor_RestartsUntilCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	fr := &fakeRunner{results: []runResult{{err: errors.New("boom")}, {err: errors.New("boom again")}}}
+	m := &stateMetrics{}
+	s := newTestSupervisor(fr)
+	s.Metrics = m
+
+	running := make(chan struct{})
+	fr.onRun = func(call int) {
+		if call == 3 {
+			close(running)
+		}
+	}
+	done := make(chan error, 1)
+	go func() { done <- s.Run(ctx) }()
+
+	<-running
+	st := s.Status()
+	require.Equal(t, StateRunning, st.State)
+	require.Equal(t, 2, st.Restarts)
+	require.Equal(t, 2, st.ConsecutiveFailures)
+	require.EqualError(t, st.LastError, "boom again")
+
+	detail, err := s.Ready()
+	require.NoError(t, err)
+	require.Equal(t, "running", detail["state"])
+	require.Equal(t, "boom again", detail["last_error"])
+
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+	require.Equal(t, StateStopped, s.Status().State)
+	require.Equal(t, 2, m.restarts)
+	require.Equal(t, []string{"running", "restarting", "running", "restarting", "running", "stopped"}, m.states)
+}
+
+func Test_Supervisor_GivesUpAfterMaxFailures(t *testing.T) {
+	fr := &fakeRunner{results: []runResult{
+		{err: errors.New("e1")},
+		{err: errors.New("e2")},
+		{err: errors.New("e3")},
+	}}
+	s := newTestSupervisor(fr)
+	s.MaxFailures = 3
+
+	err := s.Run(context.Background())
+	require.ErrorIs(t, err, ErrTooManyFailures)
+	require.ErrorContains(t, err, "e3")
+	require.Equal(t, 3, fr.calls)
+
+	st := s.Status()
+	require.Equal(t, StateFailed, st.State)
+	require.Equal(t, 2, st.Restarts)
+
+	detail, err := s.Ready()
+	require.EqualError(t, err, "consumer failed: e3")
+	require.Equal(t, "failed", detail["state"])
+	require.Equal(t, 3, detail["consecutive_failures"])
+}
+
+func Test_Supervisor_ProgressResetsFailureStreak(t *testing.T) {
+	fr := &fakeRunner{results: []runResult{
+		{err: errors.New("e1")},
+		{err: errors.New("e2"), committed: true},
+		{err: errors.New("e3")},
+		{err: errors.New("e4")},
+	}}
+	s := newTestSupervisor(fr)
+	s.MaxFailures = 2
+
+	err := s.Run(context.Background())
+	require.ErrorIs(t, err, ErrTooManyFailures)
+	require.Equal(t, 3, fr.calls, "после коммита счётчик подряд идущих падений сбрасывается")
+	require.Equal(t, 2, s.Status().ConsecutiveFailures)
+}
+
+func Test_Supervisor_HaltedMessageRefetched_GivesUp(t *testing.T) {
+	halted := fmt.Errorf("%w at t[0]#5 (u1): db down", ErrHalted)
+	fr := &fakeRunner{results: []runResult{
+		{err: halted, fetched: true},
+		{err: halted, fetched: true},
+		{err: halted, fetched: true},
+		{err: halted, fetched: true},
+	}}
+	s := newTestSupervisor(fr)
+	s.MaxFailures = 3
+
+	err := s.Run(context.Background())
+	require.ErrorIs(t, err, ErrTooManyFailures)
+	require.ErrorIs(t, err, ErrHalted)
+	require.Equal(t, 3, fr.calls, "fetch того же сообщения без коммита — не прогресс")
+}
+
+func Test_Supervisor_HaltedMessage_ConsumerRefetches(t *testing.T) {
+	newReaderOrig := newReader
+	defer func() { newReader = newReaderOrig }()
+
+	poison := kafka.Message{Topic: "t", Partition: 0, Offset: 5, Value: toJSON(t, validOrder())}
+	var fetches int
+	newReader = func(cfg kafka.ReaderConfig) reader {
+		fetches++
+		return &fakeReader{steps: []step{{msg: poison}}}
+	}
+
+	c := newRetryConsumer(&flakyRepo{failures: 1000})
+	c.Brokers, c.Topic, c.Group = []string{"dummy:9092"}, "t", "g"
+	c.Workers, c.BatchSize = 1, 1
+	s := newTestSupervisor(c)
+	s.MaxFailures = 3
+
+	err := s.Run(context.Background())
+	require.ErrorIs(t, err, ErrTooManyFailures)
+	require.ErrorIs(t, err, ErrHalted)
+	require.Equal(t, 3, fetches)
+}
+
+func Test_Supervisor_ReadyWhileRestarting(t *testing.T) {
+	fr := &fakeRunner{results: []runResult{{err: errors.New("broker down")}}}
+	s := newTestSupervisor(fr)
+	s.RestartBase = time.Hour
+	s.RestartMax = time.Hour
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- s.Run(ctx) }()
+
+	require.Eventually(t, func() bool { return s.Status().State == StateRestarting }, time.Second, time.Millisecond)
+	detail, err := s.Ready()
+	require.EqualError(t, err, "consumer restarting: broker down")
+	require.Contains(t, detail, "next_restart")
+
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+	require.Equal(t, StateStopped, s.Status().State)
+}
+
+func Test_Supervisor_restartDelay(t *testing.T) {
+	s := &Supervisor{RestartBase: 100 * time.Millisecond, RestartMax: time.Second}
+	require.Equal(t, 100*time.Millisecond, s.restartDelay(1))
+	require.Equal(t, 200*time.Millisecond, s.restartDelay(2))
+	require.Equal(t, 800*time.Millisecond, s.restartDelay(4))
+	require.Equal(t, time.Second, s.restartDelay(5))
+	require.Equal(t, time.Second, s.restartDelay(50))
+
+	s.RestartMax = 0
+	require.Equal(t, restartMax, s.restartDelay(100), "без потолка пауза не переполняется")
+
+	s.RestartBase = 0
+	require.Zero(t, s.restartDelay(3))
+}
@@ -0,0 +1,94 @@
+package kafka
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+var (
+	spansOnce sync.Once
+	spans     *tracetest.SpanRecorder
+)
+
+func recordSpans() *tracetest.SpanRecorder {
+	spansOnce.Do(func() {
+		spans = tracetest.NewSpanRecorder()
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)))
+		otel.SetTextMapPropagator(propagation.TraceContext{})
+	})
+	return spans
+}
+
+func withTraceparent(msg kafka.Message, traceID, spanID string) kafka.Message {
+	msg.Headers = append(msg.Headers, kafka.Header{Key: "traceparent", Value: []byte("00-" + traceID + "-" + spanID + "-01")})
+	return msg
+}
+
+func Test_headerCarrier(t *testing.T) {
+	var hdrs []kafka.Header
+	c := headerCarrier{&hdrs}
+	c.Set("traceparent", "a")
+	c.Set("tracestate", "b")
+	c.Set("traceparent", "c")
+	require.Equal(t, "c", c.Get("traceparent"))
+	require.Equal(t, "", c.Get("missing"))
+	require.Equal(t, []string{"traceparent", "tracestate"}, c.Keys())
+}
+
+func Test_handleMessage_ContinuesTraceFromHeaders(t *testing.T) {
+	rec := recordSpans()
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	c := newBatchConsumer(&batchRepo{})
+
+	msg := withTraceparent(orderMsg(t, "a", 7), traceID, "00f067aa0ba902b7")
+	require.NoError(t, c.handleMessage(context.Background(), &fakeReader{}, msg))
+
+	var found bool
+	for _, s := range rec.Ended() {
+		if s.SpanContext().TraceID().String() != traceID {
+			continue
+		}
+		found = true
+		require.Equal(t, "t process", s.Name())
+		require.Equal(t, "00f067aa0ba902b7", s.Parent().SpanID().String())
+		require.NotEqual(t, codes.Error, s.Status().Code)
+	}
+	require.True(t, found, "span сообщения должен продолжать трейс из заголовков")
+}
+
+func Test_handleBatch_LinksMessageTraces(t *testing.T) {
+	rec := recordSpans()
+	const (
+		traceA = "0af7651916cd43dd8448eb211c80319c"
+		traceB = "5b8efff798038103d269b633813fc60c"
+	)
+	c := newBatchConsumer(&batchRepo{})
+	msgs := []kafka.Message{
+		withTraceparent(orderMsg(t, "a", 0), traceA, "b7ad6b7169203331"),
+		withTraceparent(orderMsg(t, "b", 1), traceB, "eee19b7ec3c1b174"),
+		orderMsg(t, "c", 2),
+	}
+	require.NoError(t, c.handleBatch(context.Background(), &fakeReader{}, msgs))
+
+	var batch sdktrace.ReadOnlySpan
+	for _, s := range rec.Ended() {
+		for _, l := range s.Links() {
+			if l.SpanContext.TraceID().String() == traceA {
+				batch = s
+			}
+		}
+	}
+	require.NotNil(t, batch, "span батча должен ссылаться на трейсы сообщений")
+	require.Len(t, batch.Links(), 2)
+	require.Equal(t, traceB, batch.Links()[1].SpanContext.TraceID().String())
+	require.False(t, batch.Parent().IsValid())
+}
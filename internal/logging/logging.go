@@ -0,0 +1,181 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+var (
+	ErrBadFormat = errors.New("log format must be text or json")
+	ErrBadLevel  = errors.New("bad log level")
+)
+
+type Config struct {
+	Format string
+	Level  string
+	Levels string
+}
+
+type Loggers struct {
+	base   slog.Handler
+	level  slog.Level
+	levels map[string]slog.Level
+}
+
+func New(w io.Writer, cfg Config) (*Loggers, error) {
+	level, err := ParseLevel(cfg.Level)
+	if err != nil {
+		return nil, err
+	}
+	levels, err := parseLevels(cfg.Levels)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := &slog.HandlerOptions{Level: slog.LevelDebug}
+	var base slog.Handler
+	switch cfg.Format {
+	case "", FormatText:
+		base = slog.NewTextHandler(w, opts)
+	case FormatJSON:
+		base = slog.NewJSONHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrBadFormat, cfg.Format)
+	}
+	return &Loggers{base: newContextHandler(base), level: level, levels: levels}, nil
+}
+
+func (l *Loggers) Component(name string) *slog.Logger {
+	level, ok := l.levels[name]
+	if !ok {
+		level = l.level
+	}
+	return slog.New(levelHandler{level: level, next: l.base}).With("component", name)
+}
+
+func ParseLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	if s == "" {
+		return slog.LevelInfo, nil
+	}
+	if err := level.UnmarshalText([]byte(s)); err != nil {
+		return 0, fmt.Errorf("%w: %q", ErrBadLevel, s)
+	}
+	return level, nil
+}
+
+func parseLevels(s string) (map[string]slog.Level, error) {
+	levels := make(map[string]slog.Level)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, lvl, ok := strings.Cut(part, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("%w: %q, want component=level", ErrBadLevel, part)
+		}
+		level, err := ParseLevel(strings.TrimSpace(lvl))
+		if err != nil {
+			return nil, err
+		}
+		levels[strings.TrimSpace(name)] = level
+	}
+	return levels, nil
+}
+
+type levelHandler struct {
+	level slog.Leveler
+	next  slog.Handler
+}
+
+func (h levelHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.level.Level() && h.next.Enabled(ctx, level)
+}
+
+func (h levelHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.next.Handle(ctx, r)
+}
+
+func (h levelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return levelHandler{level: h.level, next: h.next.WithAttrs(attrs)}
+}
+
+func (h levelHandler) WithGroup(name string) slog.Handler {
+	return levelHandler{level: h.level, next: h.next.WithGroup(name)}
+}
+
+type ctxAttrsKey struct{}
+
+func WithAttrs(ctx context.Context, attrs ...slog.Attr) context.Context {
+	prev, _ := ctx.Value(ctxAttrsKey{}).([]slog.Attr)
+	merged := make([]slog.Attr, 0, len(prev)+len(attrs))
+	merged = append(merged, prev...)
+	merged = append(merged, attrs...)
+	return context.WithValue(ctx, ctxAttrsKey{}, merged)
+}
+
+// contextHandler adds request-scoped attrs from ctx at the top level of the
+// record, even when the logger was derived with WithGroup.
+type contextHandler struct {
+	root    slog.Handler
+	next    slog.Handler
+	ops     []func(slog.Handler) slog.Handler
+	grouped bool
+}
+
+func newContextHandler(h slog.Handler) contextHandler {
+	return contextHandler{root: h, next: h}
+}
+
+func (h contextHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	attrs, _ := ctx.Value(ctxAttrsKey{}).([]slog.Attr)
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		attrs = append(attrs[:len(attrs):len(attrs)],
+			slog.String("trace_id", sc.TraceID().String()), slog.String("span_id", sc.SpanID().String()))
+	}
+	if len(attrs) == 0 {
+		return h.next.Handle(ctx, r)
+	}
+	if !h.grouped {
+		r.AddAttrs(attrs...)
+		return h.next.Handle(ctx, r)
+	}
+	next := h.root.WithAttrs(attrs)
+	for _, op := range h.ops {
+		next = op(next)
+	}
+	return next.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return h.with(h.next.WithAttrs(attrs), func(n slog.Handler) slog.Handler { return n.WithAttrs(attrs) }, false)
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return h.with(h.next.WithGroup(name), func(n slog.Handler) slog.Handler { return n.WithGroup(name) }, true)
+}
+
+func (h contextHandler) with(next slog.Handler, op func(slog.Handler) slog.Handler, group bool) contextHandler {
+	return contextHandler{
+		root:    h.root,
+		next:    next,
+		ops:     append(h.ops[:len(h.ops):len(h.ops)], op),
+		grouped: h.grouped || group,
+	}
+}
@@ -0,0 +1,103 @@
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/mrussa/L0/internal/logging"
+)
+
+func TestNew_FormatsAndErrors(t *testing.T) {
+	var buf bytes.Buffer
+	logs, err := logging.New(&buf, logging.Config{})
+	require.NoError(t, err)
+	logs.Component("app").Info("hello", "n", 1)
+	require.Contains(t, buf.String(), "level=INFO msg=hello component=app n=1")
+
+	buf.Reset()
+	logs, err = logging.New(&buf, logging.Config{Format: logging.FormatJSON})
+	require.NoError(t, err)
+	logs.Component("app").Info("hello")
+	require.Contains(t, buf.String(), `"msg":"hello","component":"app"`)
+
+	_, err = logging.New(&buf, logging.Config{Format: "xml"})
+	require.ErrorIs(t, err, logging.ErrBadFormat)
+	_, err = logging.New(&buf, logging.Config{Level: "loud"})
+	require.ErrorIs(t, err, logging.ErrBadLevel)
+	_, err = logging.New(&buf, logging.Config{Levels: "kafka"})
+	require.ErrorIs(t, err, logging.ErrBadLevel)
+	_, err = logging.New(&buf, logging.Config{Levels: "kafka=loud"})
+	require.ErrorIs(t, err, logging.ErrBadLevel)
+}
+
+func TestComponentLevels(t *testing.T) {
+	var buf bytes.Buffer
+	logs, err := logging.New(&buf, logging.Config{Level: "warn", Levels: " kafka=debug , cache=error "})
+	require.NoError(t, err)
+
+	logs.Component("http").Info("hidden")
+	logs.Component("http").Warn("shown-http")
+	logs.Component("kafka").Debug("shown-kafka")
+	logs.Component("cache").Warn("hidden")
+	logs.Component("cache").With("x", 1).Error("shown-cache")
+
+	out := buf.String()
+	require.NotContains(t, out, "hidden")
+	require.Contains(t, out, "shown-http")
+	require.Contains(t, out, "shown-kafka")
+	require.Contains(t, out, "msg=shown-cache component=cache x=1")
+}
+
+func TestContextAttrsAndTrace(t *testing.T) {
+	var buf bytes.Buffer
+	logs, err := logging.New(&buf, logging.Config{})
+	require.NoError(t, err)
+	log := logs.Component("http")
+
+	ctx := logging.WithAttrs(context.Background(), slog.String("request_id", "r1"))
+	ctx = logging.WithAttrs(ctx, slog.String("order_uid", "u1"))
+	tid, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	sid, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx = trace.ContextWithSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{TraceID: tid, SpanID: sid}))
+
+	log.InfoContext(ctx, "served")
+	require.Contains(t, buf.String(), "request_id=r1 order_uid=u1 trace_id=4bf92f3577b34da6a3ce929d0e0e4736 span_id=00f067aa0ba902b7")
+
+	buf.Reset()
+	log.Info("no ctx")
+	require.NotContains(t, buf.String(), "request_id")
+}
+
+func TestContextAttrs_StayTopLevelInGroups(t *testing.T) {
+	var buf bytes.Buffer
+	logs, err := logging.New(&buf, logging.Config{Format: logging.FormatJSON})
+	require.NoError(t, err)
+	log := logs.Component("kafka").WithGroup("kafka").With("offset", 7)
+
+	ctx := logging.WithAttrs(context.Background(), slog.String("request_id", "r1"))
+	tid, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	sid, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx = trace.ContextWithSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{TraceID: tid, SpanID: sid}))
+
+	log.InfoContext(ctx, "handled", "partition", 2)
+
+	var entry struct {
+		Component string         `json:"component"`
+		RequestID string         `json:"request_id"`
+		TraceID   string         `json:"trace_id"`
+		SpanID    string         `json:"span_id"`
+		Kafka     map[string]any `json:"kafka"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry), buf.String())
+	require.Equal(t, "kafka", entry.Component)
+	require.Equal(t, "r1", entry.RequestID, "request_id не должен уезжать в группу")
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", entry.TraceID)
+	require.Equal(t, "00f067aa0ba902b7", entry.SpanID)
+	require.Equal(t, map[string]any{"offset": float64(7), "partition": float64(2)}, entry.Kafka)
+}
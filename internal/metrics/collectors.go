@@ -0,0 +1,90 @@
+package metrics
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/mrussa/L0/internal/cache"
+)
+
+type cacheCollector struct {
+	stats func() cache.Stats
+
+	size, maxEntries, hits, misses, evictions, expired, errors *prometheus.Desc
+}
+
+func NewCacheCollector(stats func() cache.Stats) prometheus.Collector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "cache", name), help, []string{"backend"}, nil)
+	}
+	return &cacheCollector{
+		stats:      stats,
+		size:       desc("entries", "Orders currently cached."),
+		maxEntries: desc("max_entries", "Configured cache capacity (0 means unbounded)."),
+		hits:       desc("hits_total", "Cache lookups that found an order."),
+		misses:     desc("misses_total", "Cache lookups that did not find an order."),
+		evictions:  desc("evictions_total", "Entries evicted by LRU."),
+		expired:    desc("expired_total", "Entries dropped after TTL expiry."),
+		errors:     desc("errors_total", "Cache backend errors."),
+	}
+}
+
+func (c *cacheCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{c.size, c.maxEntries, c.hits, c.misses, c.evictions, c.expired, c.errors} {
+		ch <- d
+	}
+}
+
+func (c *cacheCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.stats()
+	if s.Size >= 0 {
+		ch <- prometheus.MustNewConstMetric(c.size, prometheus.GaugeValue, float64(s.Size), s.Backend)
+	}
+	ch <- prometheus.MustNewConstMetric(c.maxEntries, prometheus.GaugeValue, float64(s.MaxEntries), s.Backend)
+	ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(s.Hits), s.Backend)
+	ch <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, float64(s.Misses), s.Backend)
+	ch <- prometheus.MustNewConstMetric(c.evictions, prometheus.CounterValue, float64(s.Evictions), s.Backend)
+	ch <- prometheus.MustNewConstMetric(c.expired, prometheus.CounterValue, float64(s.Expired), s.Backend)
+	ch <- prometheus.MustNewConstMetric(c.errors, prometheus.CounterValue, float64(s.Errors), s.Backend)
+}
+
+type poolCollector struct {
+	stat func() *pgxpool.Stat
+
+	acquired, idle, total, max, acquires, emptyAcquires, acquireDuration, waitDuration *prometheus.Desc
+}
+
+func NewPoolCollector(stat func() *pgxpool.Stat) prometheus.Collector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "db_pool", name), help, nil, nil)
+	}
+	return &poolCollector{
+		stat:            stat,
+		acquired:        desc("acquired_conns", "Connections currently checked out."),
+		idle:            desc("idle_conns", "Idle connections in the pool."),
+		total:           desc("total_conns", "Total connections in the pool."),
+		max:             desc("max_conns", "Maximum pool size."),
+		acquires:        desc("acquires_total", "Successful connection acquires."),
+		emptyAcquires:   desc("empty_acquires_total", "Acquires that had to wait for a connection."),
+		acquireDuration: desc("acquire_seconds_total", "Total time spent acquiring connections."),
+		waitDuration:    desc("acquire_wait_seconds_total", "Total time acquires spent waiting for a free connection."),
+	}
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{c.acquired, c.idle, c.total, c.max, c.acquires, c.emptyAcquires, c.acquireDuration, c.waitDuration} {
+		ch <- d
+	}
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.stat()
+	ch <- prometheus.MustNewConstMetric(c.acquired, prometheus.GaugeValue, float64(s.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(s.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(c.total, prometheus.GaugeValue, float64(s.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(c.max, prometheus.GaugeValue, float64(s.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(c.acquires, prometheus.CounterValue, float64(s.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.emptyAcquires, prometheus.CounterValue, float64(s.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.acquireDuration, prometheus.CounterValue, s.AcquireDuration().Seconds())
+	ch <- prometheus.MustNewConstMetric(c.waitDuration, prometheus.CounterValue, s.EmptyAcquireWaitTime().Seconds())
+}
@@ -0,0 +1,134 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "l0"
+
+type Metrics struct {
+	reg *prometheus.Registry
+
+	httpRequests *prometheus.CounterVec
+	httpDuration *prometheus.HistogramVec
+
+	kafkaMessages  *prometheus.CounterVec
+	kafkaLag       *prometheus.GaugeVec
+	upsertDuration *prometheus.HistogramVec
+
+	consumerState    *prometheus.GaugeVec
+	consumerRestarts prometheus.Counter
+}
+
+var consumerStates = []string{"starting", "running", "restarting", "failed", "stopped"}
+
+func New() *Metrics {
+	m := &Metrics{
+		reg: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "HTTP requests by route, method and status code.",
+		}, []string{"route", "method", "code"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "HTTP request latency by route and method.",
+			Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"route", "method"}),
+		kafkaMessages: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "kafka",
+			Name:      "messages_total",
+			Help:      "Kafka messages by processing stage: consumed, decoded, invalid, stored.",
+		}, []string{"stage"}),
+		kafkaLag: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "kafka",
+			Name:      "consumer_lag",
+			Help:      "Messages behind the partition high watermark as of the last fetched message.",
+		}, []string{"partition"}),
+		upsertDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "db",
+			Name:      "upsert_duration_seconds",
+			Help:      "Order upsert latency, single order or batch.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"mode"}),
+		consumerState: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "kafka",
+			Name:      "consumer_state",
+			Help:      "1 for the current consumer supervisor state, 0 for the others.",
+		}, []string{"state"}),
+		consumerRestarts: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "kafka",
+			Name:      "consumer_restarts_total",
+			Help:      "Consumer restarts performed by the supervisor.",
+		}),
+	}
+	m.reg.MustRegister(
+		m.httpRequests, m.httpDuration,
+		m.kafkaMessages, m.kafkaLag, m.upsertDuration,
+		m.consumerState, m.consumerRestarts,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	for _, stage := range []string{"consumed", "decoded", "invalid", "stored"} {
+		m.kafkaMessages.WithLabelValues(stage)
+	}
+	m.SetConsumerState("starting")
+	return m
+}
+
+func (m *Metrics) Register(c prometheus.Collector) {
+	m.reg.MustRegister(c)
+}
+
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.reg, promhttp.HandlerOpts{Registry: m.reg})
+}
+
+func (m *Metrics) ObserveHTTP(route, method string, code int, d time.Duration) {
+	m.httpRequests.WithLabelValues(route, method, strconv.Itoa(code)).Inc()
+	m.httpDuration.WithLabelValues(route, method).Observe(d.Seconds())
+}
+
+func (m *Metrics) Messages(stage string, n int) {
+	m.kafkaMessages.WithLabelValues(stage).Add(float64(n))
+}
+
+func (m *Metrics) SetLag(partition int, lag int64) {
+	m.kafkaLag.WithLabelValues(strconv.Itoa(partition)).Set(float64(max(lag, 0)))
+}
+
+func (m *Metrics) ObserveUpsert(batch bool, d time.Duration) {
+	mode := "single"
+	if batch {
+		mode = "batch"
+	}
+	m.upsertDuration.WithLabelValues(mode).Observe(d.Seconds())
+}
+
+func (m *Metrics) SetConsumerState(state string) {
+	for _, s := range consumerStates {
+		v := 0.0
+		if s == state {
+			v = 1
+		}
+		m.consumerState.WithLabelValues(s).Set(v)
+	}
+}
+
+func (m *Metrics) ConsumerRestarted() {
+	m.consumerRestarts.Inc()
+}
@@ -0,0 +1,100 @@
+package metrics_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrussa/L0/internal/cache"
+	"github.com/mrussa/L0/internal/metrics"
+	"github.com/mrussa/L0/internal/repo"
+)
+
+func scrape(t *testing.T, m *metrics.Metrics) string {
+	t.Helper()
+	rr := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	b, err := io.ReadAll(rr.Body)
+	require.NoError(t, err)
+	return string(b)
+}
+
+func TestMetrics_Exposition(t *testing.T) {
+	m := metrics.New()
+	m.ObserveHTTP("/order/", http.MethodGet, http.StatusOK, 3*time.Millisecond)
+	m.ObserveHTTP("/order/", http.MethodGet, http.StatusNotFound, time.Millisecond)
+	m.Messages("consumed", 3)
+	m.Messages("stored", 2)
+	m.SetLag(1, 42)
+	m.SetLag(2, -1)
+	m.ObserveUpsert(true, 20*time.Millisecond)
+	m.SetConsumerState("restarting")
+	m.ConsumerRestarted()
+
+	c := cache.New(cache.WithMaxEntries(10))
+	c.Set("a", repo.Order{OrderUID: "a"})
+	c.Get("a")
+	c.Get("b")
+	m.Register(metrics.NewCacheCollector(c.Stats))
+
+	out := scrape(t, m)
+	for _, want := range []string{
+		`l0_http_requests_total{code="200",method="GET",route="/order/"} 1`,
+		`l0_http_requests_total{code="404",method="GET",route="/order/"} 1`,
+		`l0_http_request_duration_seconds_count{method="GET",route="/order/"} 2`,
+		`l0_kafka_messages_total{stage="consumed"} 3`,
+		`l0_kafka_messages_total{stage="invalid"} 0`,
+		`l0_kafka_messages_total{stage="stored"} 2`,
+		`l0_kafka_consumer_lag{partition="1"} 42`,
+		`l0_kafka_consumer_lag{partition="2"} 0`,
+		`l0_db_upsert_duration_seconds_count{mode="batch"} 1`,
+		`l0_kafka_consumer_state{state="restarting"} 1`,
+		`l0_kafka_consumer_state{state="running"} 0`,
+		`l0_kafka_consumer_restarts_total 1`,
+		`l0_cache_entries{backend="memory"} 1`,
+		`l0_cache_max_entries{backend="memory"} 10`,
+		`l0_cache_hits_total{backend="memory"} 1`,
+		`l0_cache_misses_total{backend="memory"} 1`,
+		`go_goroutines`,
+	} {
+		require.True(t, strings.Contains(out, want), "нет строки %q", want)
+	}
+}
+
+func TestCacheCollector_UnknownSizeSkipped(t *testing.T) {
+	m := metrics.New()
+	m.Register(metrics.NewCacheCollector(func() cache.Stats {
+		return cache.Stats{Backend: "redis", Size: cache.SizeUnknown, Hits: 3}
+	}))
+
+	out := scrape(t, m)
+	require.NotContains(t, out, `l0_cache_entries{backend="redis"}`)
+	require.Contains(t, out, `l0_cache_hits_total{backend="redis"} 3`)
+}
+
+func TestPoolCollector(t *testing.T) {
+	pool, err := pgxpool.New(context.Background(), "postgres://u:p@127.0.0.1:1/db?connect_timeout=1")
+	require.NoError(t, err)
+	defer pool.Close()
+
+	m := metrics.New()
+	m.Register(metrics.NewPoolCollector(pool.Stat))
+
+	out := scrape(t, m)
+	for _, want := range []string{
+		"l0_db_pool_acquired_conns 0",
+		"l0_db_pool_idle_conns 0",
+		"l0_db_pool_max_conns ",
+		"l0_db_pool_acquire_wait_seconds_total 0",
+	} {
+		require.True(t, strings.Contains(out, want), "нет строки %q", want)
+	}
+}
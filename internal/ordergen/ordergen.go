@@ -0,0 +1,185 @@
+package ordergen
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/mrussa/L0/internal/repo"
+)
+
+const (
+	maxItems     = 12
+	customerPool = 500
+	createdSpan  = 30 * 24 * time.Hour
+)
+
+type market struct {
+	locale   string
+	currency string
+	phone    string
+	cities   []string
+	regions  []string
+}
+
+var markets = []market{
+	{"ru", "RUB", "+7", []string{"Moscow", "Kazan", "Novosibirsk", "Yekaterinburg", "Samara"}, []string{"Moscow", "Tatarstan", "Novosibirsk Oblast", "Sverdlovsk Oblast", "Samara Oblast"}},
+	{"kk", "KZT", "+7", []string{"Almaty", "Astana", "Shymkent"}, []string{"Almaty", "Akmola", "Turkistan"}},
+	{"be", "BYN", "+375", []string{"Minsk", "Brest", "Gomel"}, []string{"Minsk", "Brest Region", "Gomel Region"}},
+	{"en", "USD", "+1", []string{"New York", "Austin", "Seattle"}, []string{"NY", "TX", "WA"}},
+	{"de", "EUR", "+49", []string{"Berlin", "Hamburg", "Munich"}, []string{"Berlin", "Hamburg", "Bavaria"}},
+	{"he", "ILS", "+972", []string{"Kiryat Mozkin", "Haifa", "Tel Aviv"}, []string{"Kraiot", "Haifa", "Tel Aviv"}},
+}
+
+var (
+	deliveryServices = []string{"meest", "cdek", "dhl", "boxberry", "pochta", "dpd"}
+	providers        = []string{"wbpay", "sberpay", "tpay", "applepay"}
+	banks            = []string{"alpha", "sber", "tinkoff", "vtb", "raiffeisen"}
+	firstNames       = []string{"Ivan", "Anna", "Test", "Maria", "Alex", "Olga", "Dmitry", "Elena", "John", "Sara"}
+	lastNames        = []string{"Ivanov", "Petrova", "Testov", "Smirnova", "Kuznetsov", "Cohen", "Miller", "Schmidt"}
+	streets          = []string{"Ploshad Mira", "Lenina", "Main St", "Hauptstrasse", "Sadovaya", "Abaya"}
+	products         = []string{"Mascaras", "Lipstick", "Sneakers", "T-shirt", "Phone case", "Backpack", "Headphones", "Mug", "Notebook", "Socks"}
+	brands           = []string{"Vivienne Sabo", "Nike", "Adidas", "Xiaomi", "Samsung", "Zara", "Ikea", "Bic"}
+	sizes            = []string{"0", "XS", "S", "M", "L", "XL", "42"}
+	itemStatuses     = []int32{202, 200, 201, 300}
+)
+
+// Generator produces deterministic orders: the same Seed and index always
+// yield the same order, so a reader can re-derive UIDs a writer produced.
+type Generator struct {
+	Seed uint64
+	Now  time.Time
+}
+
+func New(seed uint64) Generator {
+	return Generator{Seed: seed, Now: time.Now().UTC()}
+}
+
+func (g Generator) UID(i int) string {
+	h := fnv.New64a()
+	var b [16]byte
+	binary.BigEndian.PutUint64(b[:8], g.Seed)
+	binary.BigEndian.PutUint64(b[8:], uint64(i))
+	_, _ = h.Write(b[:])
+	return hex.EncodeToString(h.Sum(nil)) + "lg"
+}
+
+func (g Generator) Order(i int) repo.Order {
+	rnd := rand.New(rand.NewPCG(g.Seed, uint64(i)))
+	m := markets[rnd.IntN(len(markets))]
+	uid := g.UID(i)
+	track := "WBIL" + upper(rnd, 10)
+	created := g.Now.Add(-time.Duration(rnd.Int64N(int64(createdSpan)))).Truncate(time.Second)
+
+	items := make([]repo.Item, 1+itemCount(rnd))
+	var goods int32
+	for k := range items {
+		price := int32(50 + rnd.IntN(20000))
+		sale := int32(0)
+		if rnd.IntN(3) == 0 {
+			sale = int32(5 * (1 + rnd.IntN(14)))
+		}
+		total := price * (100 - sale) / 100
+		goods += total
+		items[k] = repo.Item{
+			ChrtID:      int64(1_000_000 + rnd.IntN(9_000_000)),
+			TrackNumber: track,
+			Price:       price,
+			RID:         hexString(rnd, 16) + "test",
+			Name:        pick(rnd, products),
+			Sale:        sale,
+			Size:        pick(rnd, sizes),
+			TotalPrice:  total,
+			NmID:        int64(1_000_000 + rnd.IntN(9_000_000)),
+			Brand:       pick(rnd, brands),
+			Status:      itemStatuses[rnd.IntN(len(itemStatuses))],
+		}
+	}
+
+	var deliveryCost, customFee int32
+	if rnd.IntN(4) != 0 {
+		deliveryCost = int32(100 * rnd.IntN(20))
+	}
+	if rnd.IntN(10) == 0 {
+		customFee = int32(10 * rnd.IntN(50))
+	}
+
+	first, last := pick(rnd, firstNames), pick(rnd, lastNames)
+	city := rnd.IntN(len(m.cities))
+
+	return repo.Order{
+		OrderUID:          uid,
+		TrackNumber:       track,
+		Entry:             "WBIL",
+		Locale:            m.locale,
+		InternalSignature: "",
+		CustomerID:        fmt.Sprintf("cust-%d", rnd.IntN(customerPool)),
+		DeliveryService:   pick(rnd, deliveryServices),
+		ShardKey:          fmt.Sprint(rnd.IntN(10)),
+		SMID:              int32(rnd.IntN(100)),
+		DateCreated:       created,
+		OofShard:          fmt.Sprint(1 + rnd.IntN(3)),
+		Delivery: repo.Delivery{
+			Name:    first + " " + last,
+			Phone:   m.phone + digits(rnd, 9),
+			Zip:     digits(rnd, 6),
+			City:    m.cities[city],
+			Address: fmt.Sprintf("%s %d", pick(rnd, streets), 1+rnd.IntN(200)),
+			Region:  m.regions[city],
+			Email:   strings.ToLower(first+"."+last) + fmt.Sprintf("%d@example.com", rnd.IntN(1000)),
+		},
+		Payment: repo.Payment{
+			TransactionID: uid,
+			RequestID:     "",
+			Currency:      m.currency,
+			Provider:      pick(rnd, providers),
+			Amount:        goods + deliveryCost + customFee,
+			PaymentDT:     created.Unix(),
+			Bank:          pick(rnd, banks),
+			DeliveryCost:  deliveryCost,
+			GoodsTotal:    goods,
+			CustomFee:     customFee,
+		},
+		Items: items,
+	}
+}
+
+// itemCount is skewed towards small baskets: most orders have 1-3 items.
+func itemCount(rnd *rand.Rand) int {
+	n := 0
+	for n < maxItems-1 && rnd.IntN(100) < 45 {
+		n++
+	}
+	return n
+}
+
+func pick(rnd *rand.Rand, xs []string) string { return xs[rnd.IntN(len(xs))] }
+
+func upper(rnd *rand.Rand, n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte('A' + rnd.IntN(26))
+	}
+	return string(b)
+}
+
+func digits(rnd *rand.Rand, n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte('0' + rnd.IntN(10))
+	}
+	return string(b)
+}
+
+func hexString(rnd *rand.Rand, n int) string {
+	const hexDigits = "0123456789abcdef"
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = hexDigits[rnd.IntN(16)]
+	}
+	return string(b)
+}
@@ -0,0 +1,55 @@
+package ordergen_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrussa/L0/internal/ordergen"
+)
+
+func TestOrder_Deterministic(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	g1 := ordergen.Generator{Seed: 7, Now: now}
+	g2 := ordergen.Generator{Seed: 7, Now: now}
+
+	require.Equal(t, g1.Order(3), g2.Order(3))
+	require.Equal(t, g1.UID(3), g1.Order(3).OrderUID)
+	require.NotEqual(t, g1.UID(3), g1.UID(4))
+	require.NotEqual(t, g1.UID(3), ordergen.Generator{Seed: 8}.UID(3))
+}
+
+func TestOrder_ConsistentTotals(t *testing.T) {
+	t.Parallel()
+	g := ordergen.New(42)
+
+	currencies := map[string]bool{}
+	itemCounts := map[int]bool{}
+	for i := 0; i < 500; i++ {
+		o := g.Order(i)
+
+		require.NotEmpty(t, o.OrderUID)
+		require.LessOrEqual(t, len(o.OrderUID), 100)
+		require.NotEmpty(t, o.TrackNumber)
+		require.NotEmpty(t, o.Items)
+		require.False(t, o.DateCreated.After(g.Now))
+
+		var goods int32
+		for _, it := range o.Items {
+			require.Equal(t, o.TrackNumber, it.TrackNumber)
+			require.Equal(t, it.Price*(100-it.Sale)/100, it.TotalPrice)
+			goods += it.TotalPrice
+		}
+		require.Equal(t, goods, o.Payment.GoodsTotal)
+		require.Equal(t, o.Payment.GoodsTotal+o.Payment.DeliveryCost+o.Payment.CustomFee, o.Payment.Amount)
+		require.Equal(t, o.DateCreated.Unix(), o.Payment.PaymentDT)
+		require.Contains(t, o.Delivery.Email, "@")
+
+		currencies[o.Payment.Currency] = true
+		itemCounts[len(o.Items)] = true
+	}
+	require.Greater(t, len(currencies), 3, "валюты должны варьироваться")
+	require.Greater(t, len(itemCounts), 3, "число позиций должно варьироваться")
+}
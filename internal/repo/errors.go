@@ -1,14 +1,38 @@
 package repo
 
-import "errors"
+import (
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
 
 var (
 	ErrNotFound     = errors.New("order not found")
 	ErrBadUID       = errors.New("bad order_uid")
 	ErrInconsistent = errors.New("inconsistent data")
+	ErrBadCursor    = errors.New("bad cursor")
+	ErrBadLookup    = errors.New("bad lookup")
+	ErrBadQuery     = errors.New("bad search query")
 )
 
+// IsPermanent reports whether a write failed because of the order itself,
+// so retrying it can never succeed: bad uid, inconsistent data, or a
+// Postgres data exception (class 22) or integrity violation (class 23).
+func IsPermanent(err error) bool {
+	if errors.Is(err, ErrBadUID) || errors.Is(err, ErrInconsistent) {
+		return true
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return strings.HasPrefix(pgErr.Code, "22") || strings.HasPrefix(pgErr.Code, "23")
+	}
+	return false
+}
+
 const (
 	maxUIDLen       = 100
 	defaultItemsCap = 8
+	defaultPageSize = 20
+	maxPageSize     = 100
 )
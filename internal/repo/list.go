@@ -0,0 +1,120 @@
+package repo
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func (r *OrdersRepo) ListOrders(ctx context.Context, f OrderFilter) (_ OrdersPage, err error) {
+	ctx, span := startSpan(ctx, "ListOrders")
+	defer func() { endSpan(span, err) }()
+
+	limit := f.Limit
+	if limit <= 0 {
+		limit = defaultPageSize
+	}
+	limit = min(limit, maxPageSize)
+
+	var (
+		conds []string
+		args  []any
+	)
+	add := func(cond string, v any) {
+		args = append(args, v)
+		conds = append(conds, strings.ReplaceAll(cond, "?", "$"+strconv.Itoa(len(args))))
+	}
+	if f.CustomerID != "" {
+		add("o.customer_id = ?", f.CustomerID)
+	}
+	if f.TrackNumber != "" {
+		add("o.track_number = ?", f.TrackNumber)
+	}
+	if f.DeliveryService != "" {
+		add("o.delivery_service = ?", f.DeliveryService)
+	}
+	if f.Currency != "" {
+		add("p.currency = ?", f.Currency)
+	}
+	if f.Provider != "" {
+		add("p.provider = ?", f.Provider)
+	}
+	if !f.CreatedFrom.IsZero() {
+		add("o.date_created >= ?", f.CreatedFrom.UTC())
+	}
+	if !f.CreatedTo.IsZero() {
+		add("o.date_created < ?", f.CreatedTo.UTC())
+	}
+	if f.Cursor != "" {
+		ts, uid, err := decodeCursor(f.Cursor)
+		if err != nil {
+			return OrdersPage{}, err
+		}
+		args = append(args, ts, uid)
+		conds = append(conds, fmt.Sprintf("(o.date_created, o.order_uid) < ($%d, $%d)", len(args)-1, len(args)))
+	}
+
+	var sb strings.Builder
+	sb.WriteString(qListOrders)
+	if len(conds) > 0 {
+		sb.WriteString("\nWHERE ")
+		sb.WriteString(strings.Join(conds, " AND "))
+	}
+	args = append(args, limit+1)
+	fmt.Fprintf(&sb, "\nORDER BY o.date_created DESC, o.order_uid DESC\nLIMIT $%d", len(args))
+
+	ctxT, cancel := r.withQ(ctx)
+	defer cancel()
+
+	rows, err := r.Pool.Query(ctxT, sb.String(), args...)
+	if err != nil {
+		return OrdersPage{}, fmt.Errorf("listOrders query: %w", err)
+	}
+	defer rows.Close()
+
+	page := OrdersPage{Orders: make([]OrderSummary, 0, limit)}
+	for rows.Next() {
+		var s OrderSummary
+		if err := rows.Scan(
+			&s.OrderUID, &s.TrackNumber, &s.CustomerID, &s.DeliveryService, &s.DateCreated,
+			&s.Currency, &s.Provider, &s.Amount,
+		); err != nil {
+			return OrdersPage{}, fmt.Errorf("listOrders scan: %w", err)
+		}
+		page.Orders = append(page.Orders, s)
+	}
+	if err := rows.Err(); err != nil {
+		return OrdersPage{}, fmt.Errorf("listOrders rows: %w", err)
+	}
+
+	if len(page.Orders) > limit {
+		page.Orders = page.Orders[:limit]
+		last := page.Orders[limit-1]
+		page.NextCursor = encodeCursor(last.DateCreated, last.OrderUID)
+	}
+	return page, nil
+}
+
+func encodeCursor(ts time.Time, uid string) string {
+	raw := strconv.FormatInt(ts.UTC().UnixNano(), 10) + "|" + uid
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(c string) (time.Time, string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(c)
+	if err != nil {
+		return time.Time{}, "", ErrBadCursor
+	}
+	nanos, uid, ok := strings.Cut(string(raw), "|")
+	if !ok || uid == "" || len(uid) > maxUIDLen {
+		return time.Time{}, "", ErrBadCursor
+	}
+	n, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return time.Time{}, "", ErrBadCursor
+	}
+	return time.Unix(0, n).UTC(), uid, nil
+}
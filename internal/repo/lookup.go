@@ -0,0 +1,73 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type LookupKey string
+
+const (
+	ByTrackNumber LookupKey = "track_number"
+	ByTransaction LookupKey = "transaction"
+	ByRID         LookupKey = "rid"
+	ByChrtID      LookupKey = "chrt_id"
+	ByCustomer    LookupKey = "customer_id"
+)
+
+const maxLookupLen = 255
+
+var lookupQueries = map[LookupKey]string{
+	ByTrackNumber: qByTrackNumber,
+	ByTransaction: qByTransaction,
+	ByRID:         qByRID,
+	ByChrtID:      qByChrtID,
+	ByCustomer:    qByCustomer,
+}
+
+func (r *OrdersRepo) FindOrderUIDs(ctx context.Context, key LookupKey, value string, limit int) (_ []string, err error) {
+	ctx, span := startSpan(ctx, "FindOrderUIDs", attribute.String("lookup.key", string(key)))
+	defer func() { endSpan(span, err) }()
+
+	q, ok := lookupQueries[key]
+	if !ok || value == "" || len(value) > maxLookupLen {
+		return nil, ErrBadLookup
+	}
+	var arg any = value
+	if key == ByChrtID {
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, ErrBadLookup
+		}
+		arg = n
+	}
+	if limit <= 0 {
+		limit = defaultPageSize
+	}
+	limit = min(limit, maxPageSize)
+
+	ctxT, cancel := r.withQ(ctx)
+	defer cancel()
+
+	rows, err := r.Pool.Query(ctxT, q, arg, limit)
+	if err != nil {
+		return nil, fmt.Errorf("find %s query: %w", key, err)
+	}
+	defer rows.Close()
+
+	uids := make([]string, 0, 1)
+	for rows.Next() {
+		var uid string
+		if err := rows.Scan(&uid); err != nil {
+			return nil, fmt.Errorf("find %s scan: %w", key, err)
+		}
+		uids = append(uids, uid)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("find %s rows: %w", key, err)
+	}
+	return uids, nil
+}
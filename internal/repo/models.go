@@ -55,3 +55,31 @@ type Item struct {
 	Brand       string `json:"brand"`
 	Status      int32  `json:"status"`
 }
+
+type OrderSummary struct {
+	OrderUID        string    `json:"order_uid"`
+	TrackNumber     string    `json:"track_number"`
+	CustomerID      string    `json:"customer_id"`
+	DeliveryService string    `json:"delivery_service"`
+	DateCreated     time.Time `json:"date_created"`
+	Currency        string    `json:"currency"`
+	Provider        string    `json:"provider"`
+	Amount          int32     `json:"amount"`
+}
+
+type OrderFilter struct {
+	CustomerID      string
+	TrackNumber     string
+	DeliveryService string
+	Currency        string
+	Provider        string
+	CreatedFrom     time.Time
+	CreatedTo       time.Time
+	Cursor          string
+	Limit           int
+}
+
+type OrdersPage struct {
+	Orders     []OrderSummary `json:"orders"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
@@ -2,8 +2,12 @@ package repo
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
+	"fmt"
 	"regexp"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -11,6 +15,11 @@ import (
 	"github.com/jackc/pgx/v5/pgconn"
 	pgxmock "github.com/pashagolub/pgxmock/v4"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 func tNow() time.Time {
@@ -112,173 +121,6 @@ func Test_NewOrdersRepoWith_CustomTimeouts(t *testing.T) {
 	require.WithinDuration(t, time.Now().Add(3*time.Second), dlT, 200*time.Millisecond)
 }
 
-func Test_errorsIsNoRows(t *testing.T) {
-	require.True(t, errorsIsNoRows(pgx.ErrNoRows))
-	require.False(t, errorsIsNoRows(errors.New("x")))
-}
-
-func Test_getOrderHeader_Success(t *testing.T) {
-	mock, _ := pgxmock.NewPool()
-	defer mock.Close()
-
-	uid := "uid-1"
-	rows := pgxmock.NewRows([]string{
-		"order_uid", "track_number", "entry", "locale", "internal_signature", "customer_id",
-		"delivery_service", "shardkey", "sm_id", "date_created", "oof_shard",
-	}).AddRow(uid, "TRK", "WBIL", "en", "", "cust", "meest", "9", int32(99), tNow(), "1")
-
-	mock.ExpectQuery(regexp.QuoteMeta(qOrder)).WithArgs(uid).WillReturnRows(rows)
-
-	r := &OrdersRepo{Pool: mock, qTimeout: 2 * time.Second, txTimeout: 5 * time.Second}
-	got, err := r.getOrderHeader(context.Background(), uid)
-	require.NoError(t, err)
-	require.Equal(t, uid, got.OrderUID)
-	require.Equal(t, int32(99), got.SMID)
-	require.Equal(t, tNow(), got.DateCreated)
-	require.NoError(t, mock.ExpectationsWereMet())
-}
-
-func Test_getOrderHeader_NotFound_And_OtherErrorWrapped(t *testing.T) {
-	m1, _ := pgxmock.NewPool()
-	defer m1.Close()
-	m1.ExpectQuery(regexp.QuoteMeta(qOrder)).WithArgs("nope").WillReturnError(pgx.ErrNoRows)
-	r1 := &OrdersRepo{Pool: m1, qTimeout: 2 * time.Second, txTimeout: 5 * time.Second}
-	_, err := r1.getOrderHeader(context.Background(), "nope")
-	require.ErrorIs(t, err, ErrNotFound)
-	require.NoError(t, m1.ExpectationsWereMet())
-
-	m2, _ := pgxmock.NewPool()
-	defer m2.Close()
-	m2.ExpectQuery(regexp.QuoteMeta(qOrder)).WithArgs("bad").WillReturnError(errors.New("boom"))
-	r2 := &OrdersRepo{Pool: m2, qTimeout: 2 * time.Second, txTimeout: 5 * time.Second}
-	_, err = r2.getOrderHeader(context.Background(), "bad")
-	require.Error(t, err)
-	require.ErrorContains(t, err, "getOrderHeader")
-	require.ErrorContains(t, err, "boom")
-	require.NoError(t, m2.ExpectationsWereMet())
-}
-
-func Test_getDelivery_Success_NoRows_OtherWrapped(t *testing.T) {
-	uid := "uid-1"
-
-	m1, _ := pgxmock.NewPool()
-	defer m1.Close()
-	rows := pgxmock.NewRows([]string{
-		"name", "phone", "zip", "city", "address", "region", "email",
-	}).AddRow("Name", "+1", "0", "City", "Addr", "Region", "e@mail")
-	m1.ExpectQuery(regexp.QuoteMeta(qDelivery)).WithArgs(uid).WillReturnRows(rows)
-	r1 := &OrdersRepo{Pool: m1, qTimeout: 2 * time.Second, txTimeout: 5 * time.Second}
-	d, err := r1.getDelivery(context.Background(), uid)
-	require.NoError(t, err)
-	require.Equal(t, "Name", d.Name)
-	require.NoError(t, m1.ExpectationsWereMet())
-
-	m2, _ := pgxmock.NewPool()
-	defer m2.Close()
-	m2.ExpectQuery(regexp.QuoteMeta(qDelivery)).WithArgs(uid).WillReturnError(pgx.ErrNoRows)
-	r2 := &OrdersRepo{Pool: m2, qTimeout: 2 * time.Second, txTimeout: 5 * time.Second}
-	_, err = r2.getDelivery(context.Background(), uid)
-	require.ErrorIs(t, err, ErrInconsistent)
-	require.ErrorContains(t, err, "delivery missing")
-	require.NoError(t, m2.ExpectationsWereMet())
-
-	m3, _ := pgxmock.NewPool()
-	defer m3.Close()
-	m3.ExpectQuery(regexp.QuoteMeta(qDelivery)).WithArgs(uid).WillReturnError(errors.New("boom"))
-	r3 := &OrdersRepo{Pool: m3, qTimeout: 2 * time.Second, txTimeout: 5 * time.Second}
-	_, err = r3.getDelivery(context.Background(), uid)
-	require.ErrorContains(t, err, "getDelivery")
-	require.ErrorContains(t, err, "boom")
-	require.NoError(t, m3.ExpectationsWereMet())
-}
-
-func Test_getPayment_Success_NoRows_OtherWrapped(t *testing.T) {
-	uid := "uid-1"
-
-	m1, _ := pgxmock.NewPool()
-	defer m1.Close()
-	rows := pgxmock.NewRows([]string{
-		"transaction_id", "request_id", "currency", "provider", "amount", "payment_dt",
-		"bank", "delivery_cost", "goods_total", "custom_fee",
-	}).AddRow("tx", "", "USD", "wbpay", int32(10), int64(123), "alpha", int32(1), int32(2), int32(0))
-	m1.ExpectQuery(regexp.QuoteMeta(qPayment)).WithArgs(uid).WillReturnRows(rows)
-	r1 := &OrdersRepo{Pool: m1, qTimeout: 2 * time.Second, txTimeout: 5 * time.Second}
-	p, err := r1.getPayment(context.Background(), uid)
-	require.NoError(t, err)
-	require.Equal(t, "tx", p.TransactionID)
-	require.NoError(t, m1.ExpectationsWereMet())
-
-	m2, _ := pgxmock.NewPool()
-	defer m2.Close()
-	m2.ExpectQuery(regexp.QuoteMeta(qPayment)).WithArgs(uid).WillReturnError(pgx.ErrNoRows)
-	r2 := &OrdersRepo{Pool: m2, qTimeout: 2 * time.Second, txTimeout: 5 * time.Second}
-	_, err = r2.getPayment(context.Background(), uid)
-	require.ErrorIs(t, err, ErrInconsistent)
-	require.ErrorContains(t, err, "payment missing")
-	require.NoError(t, m2.ExpectationsWereMet())
-
-	m3, _ := pgxmock.NewPool()
-	defer m3.Close()
-	m3.ExpectQuery(regexp.QuoteMeta(qPayment)).WithArgs(uid).WillReturnError(errors.New("boom"))
-	r3 := &OrdersRepo{Pool: m3, qTimeout: 2 * time.Second, txTimeout: 5 * time.Second}
-	_, err = r3.getPayment(context.Background(), uid)
-	require.ErrorContains(t, err, "getPayment")
-	require.ErrorContains(t, err, "boom")
-	require.NoError(t, m3.ExpectationsWereMet())
-}
-
-func Test_getItems_Success_ScanErr_QueryErr_RowsErr(t *testing.T) {
-	uid := "uid-1"
-
-	m1, _ := pgxmock.NewPool()
-	defer m1.Close()
-	rows := pgxmock.NewRows([]string{
-		"id", "chrt_id", "track_number", "price", "rid", "name", "sale", "size",
-		"total_price", "nm_id", "brand", "status",
-	}).AddRow(int64(1), int64(11), "TRK", int32(100), "r1", "N1", int32(0), "0", int32(100), int64(500), "B", int32(200)).
-		AddRow(int64(2), int64(22), "TRK", int32(200), "r2", "N2", int32(0), "0", int32(200), int64(600), "B", int32(200))
-	m1.ExpectQuery(regexp.QuoteMeta(qItems)).WithArgs(uid).WillReturnRows(rows)
-	r1 := &OrdersRepo{Pool: m1, qTimeout: 2 * time.Second, txTimeout: 5 * time.Second}
-	items, err := r1.getItems(context.Background(), uid)
-	require.NoError(t, err)
-	require.Len(t, items, 2)
-	require.Equal(t, int32(100), items[0].Price)
-	require.Equal(t, int32(200), items[1].Price)
-	require.NoError(t, m1.ExpectationsWereMet())
-
-	m2, _ := pgxmock.NewPool()
-	defer m2.Close()
-	badRows := pgxmock.NewRows([]string{"id"}).AddRow(int64(1))
-	m2.ExpectQuery(regexp.QuoteMeta(qItems)).WithArgs(uid).WillReturnRows(badRows)
-	r2 := &OrdersRepo{Pool: m2, qTimeout: 2 * time.Second, txTimeout: 5 * time.Second}
-	_, err = r2.getItems(context.Background(), uid)
-	require.ErrorContains(t, err, "getItems scan:")
-	require.NoError(t, m2.ExpectationsWereMet())
-
-	m3, _ := pgxmock.NewPool()
-	defer m3.Close()
-	m3.ExpectQuery(regexp.QuoteMeta(qItems)).WithArgs(uid).WillReturnError(errors.New("qerr"))
-	r3 := &OrdersRepo{Pool: m3, qTimeout: 2 * time.Second, txTimeout: 5 * time.Second}
-	_, err = r3.getItems(context.Background(), uid)
-	require.ErrorContains(t, err, "getItems query")
-	require.ErrorContains(t, err, "qerr")
-	require.NoError(t, m3.ExpectationsWereMet())
-
-	m4, _ := pgxmock.NewPool()
-	defer m4.Close()
-	rows4 := pgxmock.NewRows([]string{
-		"id", "chrt_id", "track_number", "price", "rid", "name", "sale", "size",
-		"total_price", "nm_id", "brand", "status",
-	}).AddRow(int64(1), int64(11), "TRK", int32(100), "r1", "N1", int32(0), "0", int32(100), int64(500), "B", int32(200))
-	rows4.RowError(1, errors.New("rows-err"))
-	m4.ExpectQuery(regexp.QuoteMeta(qItems)).WithArgs(uid).WillReturnRows(rows4)
-	r4 := &OrdersRepo{Pool: m4, qTimeout: 2 * time.Second, txTimeout: 5 * time.Second}
-	_, err = r4.getItems(context.Background(), uid)
-	require.ErrorContains(t, err, "getItems rows")
-	require.ErrorContains(t, err, "rows-err")
-	require.NoError(t, m4.ExpectationsWereMet())
-}
-
 func Test_ListRecentOrderUIDs_AllBranches(t *testing.T) {
 	r0 := &OrdersRepo{}
 	out, err := r0.ListRecentOrderUIDs(context.Background(), 0)
@@ -316,117 +158,136 @@ func Test_ListRecentOrderUIDs_AllBranches(t *testing.T) {
 	require.NoError(t, m3.ExpectationsWereMet())
 }
 
+func orderRows() *pgxmock.Rows {
+	return pgxmock.NewRows([]string{
+		"order_uid", "track_number", "entry", "locale", "internal_signature", "customer_id",
+		"delivery_service", "shardkey", "sm_id", "date_created", "oof_shard",
+		"payment", "delivery", "items",
+	})
+}
+
+func addOrderRow(rows *pgxmock.Rows, uid string, payment, delivery, items []byte) *pgxmock.Rows {
+	return rows.AddRow(uid, "TRK", "WBIL", "en", "", "cust", "meest", "9", int32(99), tNow(), "1",
+		payment, delivery, items)
+}
+
+var (
+	paymentJSON  = []byte(`{"transaction":"tx-1","request_id":"","currency":"USD","provider":"wbpay","amount":1817,"payment_dt":1637907727,"bank":"alpha","delivery_cost":1500,"goods_total":317,"custom_fee":0}`)
+	deliveryJSON = []byte(`{"name":"Name","phone":"+100000","zip":"000000","city":"City","address":"Addr","region":"Region","email":"n@example.com"}`)
+	itemsJSON    = []byte(`[{"chrt_id":1,"track_number":"TRK","price":317,"rid":"rid1","name":"Item1","sale":0,"size":"0","total_price":317,"nm_id":100,"brand":"Brand","status":200},{"chrt_id":2,"track_number":"TRK","price":100,"rid":"rid2","name":"Item2","sale":0,"size":"0","total_price":100,"nm_id":200,"brand":"Brand","status":200}]`)
+)
+
+func Test_GetOrder_SingleQuery_Success(t *testing.T) {
+	m, _ := pgxmock.NewPool()
+	defer m.Close()
+	m.ExpectQuery(regexp.QuoteMeta(qOrders)).WithArgs([]string{"uid-1"}).
+		WillReturnRows(addOrderRow(orderRows(), "uid-1", paymentJSON, deliveryJSON, itemsJSON))
+
+	r := &OrdersRepo{Pool: m, qTimeout: 2 * time.Second, txTimeout: 5 * time.Second}
+	o, err := r.GetOrder(context.Background(), "uid-1")
+	require.NoError(t, err)
+	require.Equal(t, sampleOrder(), o)
+	require.NoError(t, m.ExpectationsWereMet())
+}
+
 func Test_GetOrder_AllBranches(t *testing.T) {
 	r := &OrdersRepo{}
 	_, err := r.GetOrder(context.Background(), "")
 	require.ErrorIs(t, err, ErrBadUID)
-
-	long := make([]byte, maxUIDLen+1)
-	for i := range long {
-		long[i] = 'a'
-	}
-	_, err = r.GetOrder(context.Background(), string(long))
+	_, err = r.GetOrder(context.Background(), strings.Repeat("a", maxUIDLen+1))
 	require.ErrorIs(t, err, ErrBadUID)
 
 	uid := "uid-1"
+	newRepo := func(m pgxmock.PgxPoolIface) *OrdersRepo {
+		return &OrdersRepo{Pool: m, qTimeout: 2 * time.Second, txTimeout: 5 * time.Second}
+	}
 
 	m1, _ := pgxmock.NewPool()
 	defer m1.Close()
-	m1.ExpectQuery(regexp.QuoteMeta(qOrder)).WithArgs(uid).WillReturnError(pgx.ErrNoRows)
-	r1 := &OrdersRepo{Pool: m1, qTimeout: 2 * time.Second, txTimeout: 5 * time.Second}
-	_, err = r1.GetOrder(context.Background(), uid)
+	m1.ExpectQuery(regexp.QuoteMeta(qOrders)).WithArgs([]string{uid}).WillReturnRows(orderRows())
+	_, err = newRepo(m1).GetOrder(context.Background(), uid)
 	require.ErrorIs(t, err, ErrNotFound)
-	require.NoError(t, m1.ExpectationsWereMet())
 
 	m2, _ := pgxmock.NewPool()
 	defer m2.Close()
-	m2.ExpectQuery(regexp.QuoteMeta(qOrder)).WithArgs(uid).WillReturnError(errors.New("hdr-err"))
-	r2 := &OrdersRepo{Pool: m2, qTimeout: 2 * time.Second, txTimeout: 5 * time.Second}
-	_, err = r2.GetOrder(context.Background(), uid)
-	require.ErrorContains(t, err, "getOrderHeader")
-	require.ErrorContains(t, err, "hdr-err")
-	require.NoError(t, m2.ExpectationsWereMet())
+	m2.ExpectQuery(regexp.QuoteMeta(qOrders)).WithArgs([]string{uid}).WillReturnError(errors.New("q-err"))
+	_, err = newRepo(m2).GetOrder(context.Background(), uid)
+	require.ErrorContains(t, err, "getOrders query: q-err")
 
 	m3, _ := pgxmock.NewPool()
 	defer m3.Close()
-	hRows3 := pgxmock.NewRows([]string{
-		"order_uid", "track_number", "entry", "locale", "internal_signature", "customer_id",
-		"delivery_service", "shardkey", "sm_id", "date_created", "oof_shard",
-	}).AddRow(uid, "TRK", "WBIL", "en", "", "cust", "meest", "9", int32(99), tNow(), "1")
-	m3.ExpectQuery(regexp.QuoteMeta(qOrder)).WithArgs(uid).WillReturnRows(hRows3)
-	m3.ExpectQuery(regexp.QuoteMeta(qPayment)).WithArgs(uid).WillReturnError(pgx.ErrNoRows)
-	r3 := &OrdersRepo{Pool: m3, qTimeout: 2 * time.Second, txTimeout: 5 * time.Second}
-	_, err = r3.GetOrder(context.Background(), uid)
+	m3.ExpectQuery(regexp.QuoteMeta(qOrders)).WithArgs([]string{uid}).
+		WillReturnRows(addOrderRow(orderRows(), uid, nil, deliveryJSON, []byte(`[]`)))
+	_, err = newRepo(m3).GetOrder(context.Background(), uid)
 	require.ErrorIs(t, err, ErrInconsistent)
-	require.NoError(t, m3.ExpectationsWereMet())
+	require.ErrorContains(t, err, "payment missing")
 
 	m4, _ := pgxmock.NewPool()
 	defer m4.Close()
-	hRows4 := pgxmock.NewRows([]string{
-		"order_uid", "track_number", "entry", "locale", "internal_signature", "customer_id",
-		"delivery_service", "shardkey", "sm_id", "date_created", "oof_shard",
-	}).AddRow(uid, "TRK", "WBIL", "en", "", "cust", "meest", "9", int32(99), tNow(), "1")
-	m4.ExpectQuery(regexp.QuoteMeta(qOrder)).WithArgs(uid).WillReturnRows(hRows4)
-	pRows4 := pgxmock.NewRows([]string{
-		"transaction_id", "request_id", "currency", "provider", "amount", "payment_dt",
-		"bank", "delivery_cost", "goods_total", "custom_fee",
-	}).AddRow("tx", "", "USD", "wbpay", int32(10), int64(123), "alpha", int32(1), int32(2), int32(0))
-	m4.ExpectQuery(regexp.QuoteMeta(qPayment)).WithArgs(uid).WillReturnRows(pRows4)
-	m4.ExpectQuery(regexp.QuoteMeta(qDelivery)).WithArgs(uid).WillReturnError(pgx.ErrNoRows)
-	r4 := &OrdersRepo{Pool: m4, qTimeout: 2 * time.Second, txTimeout: 5 * time.Second}
-	_, err = r4.GetOrder(context.Background(), uid)
+	m4.ExpectQuery(regexp.QuoteMeta(qOrders)).WithArgs([]string{uid}).
+		WillReturnRows(addOrderRow(orderRows(), uid, paymentJSON, nil, []byte(`[]`)))
+	_, err = newRepo(m4).GetOrder(context.Background(), uid)
 	require.ErrorIs(t, err, ErrInconsistent)
-	require.NoError(t, m4.ExpectationsWereMet())
+	require.ErrorContains(t, err, "delivery missing")
 
 	m5, _ := pgxmock.NewPool()
 	defer m5.Close()
-	hRows5 := pgxmock.NewRows([]string{
-		"order_uid", "track_number", "entry", "locale", "internal_signature", "customer_id",
-		"delivery_service", "shardkey", "sm_id", "date_created", "oof_shard",
-	}).AddRow(uid, "TRK", "WBIL", "en", "", "cust", "meest", "9", int32(99), tNow(), "1")
-	m5.ExpectQuery(regexp.QuoteMeta(qOrder)).WithArgs(uid).WillReturnRows(hRows5)
-	pRows5 := pgxmock.NewRows([]string{
-		"transaction_id", "request_id", "currency", "provider", "amount", "payment_dt",
-		"bank", "delivery_cost", "goods_total", "custom_fee",
-	}).AddRow("tx", "", "USD", "wbpay", int32(10), int64(123), "alpha", int32(1), int32(2), int32(0))
-	m5.ExpectQuery(regexp.QuoteMeta(qPayment)).WithArgs(uid).WillReturnRows(pRows5)
-	dRows5 := pgxmock.NewRows([]string{
-		"name", "phone", "zip", "city", "address", "region", "email",
-	}).AddRow("Name", "+1", "0", "City", "Addr", "Region", "e@mail")
-	m5.ExpectQuery(regexp.QuoteMeta(qDelivery)).WithArgs(uid).WillReturnRows(dRows5)
-	m5.ExpectQuery(regexp.QuoteMeta(qItems)).WithArgs(uid).WillReturnError(errors.New("items-err"))
-	r5 := &OrdersRepo{Pool: m5, qTimeout: 2 * time.Second, txTimeout: 5 * time.Second}
-	_, err = r5.GetOrder(context.Background(), uid)
-	require.ErrorContains(t, err, "items-err")
-	require.NoError(t, m5.ExpectationsWereMet())
+	m5.ExpectQuery(regexp.QuoteMeta(qOrders)).WithArgs([]string{uid}).
+		WillReturnRows(addOrderRow(orderRows(), uid, paymentJSON, deliveryJSON, []byte(`{bad`)))
+	_, err = newRepo(m5).GetOrder(context.Background(), uid)
+	require.ErrorContains(t, err, "getOrders items uid-1")
 
 	m6, _ := pgxmock.NewPool()
 	defer m6.Close()
-	hRows6 := pgxmock.NewRows([]string{
-		"order_uid", "track_number", "entry", "locale", "internal_signature", "customer_id",
-		"delivery_service", "shardkey", "sm_id", "date_created", "oof_shard",
-	}).AddRow(uid, "TRK", "WBIL", "en", "", "cust", "meest", "9", int32(99), tNow(), "1")
-	m6.ExpectQuery(regexp.QuoteMeta(qOrder)).WithArgs(uid).WillReturnRows(hRows6)
-	pRows6 := pgxmock.NewRows([]string{
-		"transaction_id", "request_id", "currency", "provider", "amount", "payment_dt",
-		"bank", "delivery_cost", "goods_total", "custom_fee",
-	}).AddRow("tx", "", "USD", "wbpay", int32(10), int64(123), "alpha", int32(1), int32(2), int32(0))
-	m6.ExpectQuery(regexp.QuoteMeta(qPayment)).WithArgs(uid).WillReturnRows(pRows6)
-	dRows6 := pgxmock.NewRows([]string{
-		"name", "phone", "zip", "city", "address", "region", "email",
-	}).AddRow("Name", "+1", "0", "City", "Addr", "Region", "e@mail")
-	m6.ExpectQuery(regexp.QuoteMeta(qDelivery)).WithArgs(uid).WillReturnRows(dRows6)
-	iRows6 := pgxmock.NewRows([]string{
-		"id", "chrt_id", "track_number", "price", "rid", "name", "sale", "size",
-		"total_price", "nm_id", "brand", "status",
-	}).AddRow(int64(1), int64(11), "TRK", int32(100), "r1", "N1", int32(0), "0", int32(100), int64(500), "B", int32(200))
-	m6.ExpectQuery(regexp.QuoteMeta(qItems)).WithArgs(uid).WillReturnRows(iRows6)
-	r6 := &OrdersRepo{Pool: m6, qTimeout: 2 * time.Second, txTimeout: 5 * time.Second}
-	o, err := r6.GetOrder(context.Background(), uid)
+	m6.ExpectQuery(regexp.QuoteMeta(qOrders)).WithArgs([]string{uid}).
+		WillReturnRows(pgxmock.NewRows([]string{"order_uid"}).AddRow(uid))
+	_, err = newRepo(m6).GetOrder(context.Background(), uid)
+	require.ErrorContains(t, err, "getOrders scan")
+
+	m7, _ := pgxmock.NewPool()
+	defer m7.Close()
+	rows7 := addOrderRow(orderRows(), uid, paymentJSON, deliveryJSON, []byte(`[]`))
+	rows7.RowError(1, errors.New("rows-err"))
+	m7.ExpectQuery(regexp.QuoteMeta(qOrders)).WithArgs([]string{uid}).WillReturnRows(rows7)
+	_, err = newRepo(m7).GetOrder(context.Background(), uid)
+	require.ErrorContains(t, err, "getOrders rows: rows-err")
+}
+
+func Test_GetOrder_NoItems_EmptySlice(t *testing.T) {
+	m, _ := pgxmock.NewPool()
+	defer m.Close()
+	m.ExpectQuery(regexp.QuoteMeta(qOrders)).WithArgs([]string{"uid-1"}).
+		WillReturnRows(addOrderRow(orderRows(), "uid-1", paymentJSON, deliveryJSON, []byte(`[]`)))
+	r := &OrdersRepo{Pool: m, qTimeout: 2 * time.Second, txTimeout: 5 * time.Second}
+	o, err := r.GetOrder(context.Background(), "uid-1")
 	require.NoError(t, err)
-	require.Equal(t, uid, o.OrderUID)
-	require.Len(t, o.Items, 1)
-	require.NoError(t, m6.ExpectationsWereMet())
+	require.NotNil(t, o.Items)
+	require.Empty(t, o.Items)
+}
+
+func Test_GetOrders_Bulk(t *testing.T) {
+	r0 := &OrdersRepo{}
+	out, err := r0.GetOrders(context.Background(), nil)
+	require.NoError(t, err)
+	require.Empty(t, out)
+	_, err = r0.GetOrders(context.Background(), []string{"ok", ""})
+	require.ErrorIs(t, err, ErrBadUID)
+
+	m, _ := pgxmock.NewPool()
+	defer m.Close()
+	rows := orderRows()
+	addOrderRow(rows, "u2", paymentJSON, deliveryJSON, itemsJSON)
+	addOrderRow(rows, "u1", paymentJSON, deliveryJSON, []byte(`[]`))
+	m.ExpectQuery(regexp.QuoteMeta(qOrders)).WithArgs([]string{"u1", "u2", "missing"}).WillReturnRows(rows)
+
+	r := &OrdersRepo{Pool: m, qTimeout: 2 * time.Second, txTimeout: 5 * time.Second}
+	out, err = r.GetOrders(context.Background(), []string{"u1", "u2", "missing"})
+	require.NoError(t, err)
+	require.Len(t, out, 2)
+	require.Equal(t, "u2", out[0].OrderUID)
+	require.Len(t, out[0].Items, 2)
+	require.Equal(t, "u1", out[1].OrderUID)
+	require.NoError(t, m.ExpectationsWereMet())
 }
 
 func Test_Ping_Success_And_Error(t *testing.T) {
@@ -472,6 +333,14 @@ type fakeTxBatch struct {
 	rolledBack    bool
 	committed     bool
 	panicOnCommit bool
+
+	batch *pgx.Batch
+
+	copyTable pgx.Identifier
+	copyCols  []string
+	copied    [][]any
+	copyErr   error
+	copyShort int64
 }
 
 func (t *fakeTxBatch) Begin(context.Context) (pgx.Tx, error) { return t, nil }
@@ -483,14 +352,27 @@ func (t *fakeTxBatch) Exec(context.Context, string, ...any) (pgconn.CommandTag,
 }
 func (t *fakeTxBatch) Query(context.Context, string, ...any) (pgx.Rows, error) { panic("not used") }
 func (t *fakeTxBatch) QueryRow(context.Context, string, ...any) pgx.Row        { panic("not used") }
-func (t *fakeTxBatch) SendBatch(context.Context, *pgx.Batch) pgx.BatchResults {
+func (t *fakeTxBatch) SendBatch(_ context.Context, b *pgx.Batch) pgx.BatchResults {
+	t.batch = b
 	if t.br == nil {
 		t.br = &fakeBatchResults{}
 	}
 	return t.br
 }
-func (t *fakeTxBatch) CopyFrom(context.Context, pgx.Identifier, []string, pgx.CopyFromSource) (int64, error) {
-	panic("not used")
+func (t *fakeTxBatch) CopyFrom(_ context.Context, table pgx.Identifier, cols []string, src pgx.CopyFromSource) (int64, error) {
+	t.copyTable = table
+	t.copyCols = cols
+	if t.copyErr != nil {
+		return 0, t.copyErr
+	}
+	for src.Next() {
+		v, err := src.Values()
+		if err != nil {
+			return 0, err
+		}
+		t.copied = append(t.copied, v)
+	}
+	return int64(len(t.copied)) - t.copyShort, nil
 }
 func (t *fakeTxBatch) LargeObjects() pgx.LargeObjects { panic("not used") }
 func (t *fakeTxBatch) Conn() *pgx.Conn                { return nil }
@@ -652,3 +534,366 @@ func Test_ListRecentOrderUIDs_ScanError(t *testing.T) {
 	require.ErrorContains(t, err, "listRecent scan")
 	require.NoError(t, m.ExpectationsWereMet())
 }
+
+func Test_UpsertOrders_Empty_NoTx(t *testing.T) {
+	fdb := &fakeDBBatch{beginErr: errors.New("must not begin")}
+	r := &OrdersRepo{Pool: fdb, qTimeout: 2 * time.Second, txTimeout: 5 * time.Second}
+	require.NoError(t, r.UpsertOrders(context.Background(), nil))
+}
+
+func Test_UpsertOrders_Success_BatchAndCopy(t *testing.T) {
+	o1 := sampleOrder()
+	o2 := sampleOrder()
+	o2.OrderUID = "uid-2"
+	o2.Items = o2.Items[:1]
+
+	fdb := &fakeDBBatch{}
+	r := &OrdersRepo{Pool: fdb, qTimeout: 2 * time.Second, txTimeout: 5 * time.Second}
+	require.NoError(t, r.UpsertOrders(context.Background(), []Order{o1, o2}))
+
+	require.True(t, fdb.tx.committed)
+	require.False(t, fdb.tx.rolledBack)
+	require.Equal(t, 3*2+1, fdb.tx.br.(*fakeBatchResults).calls)
+	require.Equal(t, pgx.Identifier{"order_items"}, fdb.tx.copyTable)
+	require.Equal(t, itemColumns, fdb.tx.copyCols)
+	require.Len(t, fdb.tx.copied, 3)
+	require.Equal(t, "uid-1", fdb.tx.copied[0][0])
+	require.Equal(t, "uid-2", fdb.tx.copied[2][0])
+}
+
+func Test_UpsertOrders_DuplicateUID_LastWins(t *testing.T) {
+	first := sampleOrder()
+	second := sampleOrder()
+	second.Items = second.Items[:1]
+	second.Items[0].Name = "newer"
+
+	fdb := &fakeDBBatch{}
+	r := &OrdersRepo{Pool: fdb, qTimeout: 2 * time.Second, txTimeout: 5 * time.Second}
+	require.NoError(t, r.UpsertOrders(context.Background(), []Order{first, second}))
+
+	require.Equal(t, 3+1, fdb.tx.br.(*fakeBatchResults).calls)
+	require.Len(t, fdb.tx.copied, 1)
+	require.Equal(t, "newer", fdb.tx.copied[0][5])
+}
+
+func Test_UpsertOrders_NoItems_SkipsCopy(t *testing.T) {
+	o := sampleOrder()
+	o.Items = nil
+
+	fdb := &fakeDBBatch{}
+	r := &OrdersRepo{Pool: fdb, qTimeout: 2 * time.Second, txTimeout: 5 * time.Second}
+	require.NoError(t, r.UpsertOrders(context.Background(), []Order{o}))
+	require.Nil(t, fdb.tx.copyTable)
+	require.True(t, fdb.tx.committed)
+}
+
+func Test_UpsertOrders_Errors(t *testing.T) {
+	ctx := context.Background()
+	newRepo := func(f *fakeDBBatch) *OrdersRepo {
+		return &OrdersRepo{Pool: f, qTimeout: 2 * time.Second, txTimeout: 5 * time.Second}
+	}
+
+	bad := sampleOrder()
+	bad.OrderUID = ""
+	require.ErrorIs(t, newRepo(&fakeDBBatch{}).UpsertOrders(ctx, []Order{sampleOrder(), bad}), ErrBadUID)
+
+	neg := sampleOrder()
+	neg.Payment.Amount = -1
+	require.ErrorIs(t, newRepo(&fakeDBBatch{}).UpsertOrders(ctx, []Order{neg}), ErrInconsistent)
+
+	err := newRepo(&fakeDBBatch{beginErr: errors.New("begin-fail")}).UpsertOrders(ctx, []Order{sampleOrder()})
+	require.EqualError(t, err, "begin tx: begin-fail")
+
+	f1 := &fakeDBBatch{tx: &fakeTxBatch{br: &fakeBatchResults{failAt: 2}}}
+	require.ErrorContains(t, newRepo(f1).UpsertOrders(ctx, []Order{sampleOrder()}), "batch step 1")
+	require.True(t, f1.tx.rolledBack)
+
+	f2 := &fakeDBBatch{tx: &fakeTxBatch{br: &fakeBatchResults{closeErr: errors.New("close-fail")}}}
+	require.ErrorContains(t, newRepo(f2).UpsertOrders(ctx, []Order{sampleOrder()}), "batch close")
+	require.True(t, f2.tx.rolledBack)
+
+	f3 := &fakeDBBatch{tx: &fakeTxBatch{copyErr: errors.New("copy-fail")}}
+	require.ErrorContains(t, newRepo(f3).UpsertOrders(ctx, []Order{sampleOrder()}), "copy items: copy-fail")
+	require.True(t, f3.tx.rolledBack)
+	require.False(t, f3.tx.committed)
+
+	f4 := &fakeDBBatch{tx: &fakeTxBatch{copyShort: 1}}
+	require.ErrorIs(t, newRepo(f4).UpsertOrders(ctx, []Order{sampleOrder()}), ErrInconsistent)
+	require.True(t, f4.tx.rolledBack)
+
+	f5 := &fakeDBBatch{tx: &fakeTxBatch{commitErr: errors.New("commit-fail")}}
+	require.ErrorContains(t, newRepo(f5).UpsertOrders(ctx, []Order{sampleOrder()}), "commit-fail")
+}
+
+func Test_dedupeOrders_KeepsOrderOfLastOccurrence(t *testing.T) {
+	a, b, a2 := Order{OrderUID: "a"}, Order{OrderUID: "b"}, Order{OrderUID: "a", Entry: "v2"}
+	got := dedupeOrders([]Order{a, b, a2})
+	require.Equal(t, []Order{b, a2}, got)
+
+	in := []Order{a, b}
+	got = dedupeOrders(in)
+	require.Equal(t, in, got)
+	got[0].Entry = "mutated"
+	require.Empty(t, in[0].Entry, "исходный слайс не должен меняться")
+}
+
+func Test_ListOrders_FiltersAndCursor(t *testing.T) {
+	m, _ := pgxmock.NewPool()
+	defer m.Close()
+
+	t1 := time.Date(2024, 3, 2, 10, 0, 0, 0, time.UTC)
+	t2 := t1.Add(-time.Hour)
+	t3 := t1.Add(-2 * time.Hour)
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	cols := []string{"order_uid", "track_number", "customer_id", "delivery_service", "date_created", "currency", "provider", "amount"}
+	rows := pgxmock.NewRows(cols).
+		AddRow("u1", "TN1", "c1", "meest", t1, "USD", "wbpay", int32(100)).
+		AddRow("u2", "TN2", "c1", "meest", t2, "USD", "wbpay", int32(200)).
+		AddRow("u3", "TN3", "c1", "meest", t3, "USD", "wbpay", int32(300))
+
+	m.ExpectQuery(`WHERE o\.customer_id = \$1 AND p\.currency = \$2 AND o\.date_created >= \$3\s+ORDER BY o\.date_created DESC, o\.order_uid DESC\s+LIMIT \$4`).
+		WithArgs("c1", "USD", from, 3).
+		WillReturnRows(rows)
+
+	r := &OrdersRepo{Pool: m, qTimeout: 2 * time.Second, txTimeout: 5 * time.Second}
+	page, err := r.ListOrders(context.Background(), OrderFilter{CustomerID: "c1", Currency: "USD", CreatedFrom: from, Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, page.Orders, 2)
+	require.Equal(t, "u1", page.Orders[0].OrderUID)
+	require.Equal(t, int32(200), page.Orders[1].Amount)
+	require.NotEmpty(t, page.NextCursor)
+	require.NoError(t, m.ExpectationsWereMet())
+
+	ts, uid, err := decodeCursor(page.NextCursor)
+	require.NoError(t, err)
+	require.True(t, ts.Equal(t2))
+	require.Equal(t, "u2", uid)
+
+	m2, _ := pgxmock.NewPool()
+	defer m2.Close()
+	m2.ExpectQuery(`WHERE \(o\.date_created, o\.order_uid\) < \(\$1, \$2\)\s+ORDER BY .*LIMIT \$3`).
+		WithArgs(t2, "u2", 3).
+		WillReturnRows(pgxmock.NewRows(cols).AddRow("u3", "TN3", "c1", "meest", t3, "USD", "wbpay", int32(300)))
+	r2 := &OrdersRepo{Pool: m2, qTimeout: 2 * time.Second, txTimeout: 5 * time.Second}
+	page, err = r2.ListOrders(context.Background(), OrderFilter{Cursor: page.NextCursor, Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, page.Orders, 1)
+	require.Empty(t, page.NextCursor)
+	require.NoError(t, m2.ExpectationsWereMet())
+}
+
+func Test_ListOrders_LimitBounds_BadCursor_Errors(t *testing.T) {
+	cols := []string{"order_uid", "track_number", "customer_id", "delivery_service", "date_created", "currency", "provider", "amount"}
+
+	m1, _ := pgxmock.NewPool()
+	defer m1.Close()
+	m1.ExpectQuery(`ORDER BY o\.date_created DESC, o\.order_uid DESC\s+LIMIT \$1`).
+		WithArgs(defaultPageSize + 1).
+		WillReturnRows(pgxmock.NewRows(cols))
+	r1 := &OrdersRepo{Pool: m1, qTimeout: 2 * time.Second, txTimeout: 5 * time.Second}
+	page, err := r1.ListOrders(context.Background(), OrderFilter{})
+	require.NoError(t, err)
+	require.NotNil(t, page.Orders)
+	require.Empty(t, page.Orders)
+	require.NoError(t, m1.ExpectationsWereMet())
+
+	m2, _ := pgxmock.NewPool()
+	defer m2.Close()
+	m2.ExpectQuery(`LIMIT \$1`).WithArgs(maxPageSize + 1).WillReturnError(errors.New("boom"))
+	r2 := &OrdersRepo{Pool: m2, qTimeout: 2 * time.Second, txTimeout: 5 * time.Second}
+	_, err = r2.ListOrders(context.Background(), OrderFilter{Limit: 10_000})
+	require.ErrorContains(t, err, "listOrders query")
+	require.ErrorContains(t, err, "boom")
+	require.NoError(t, m2.ExpectationsWereMet())
+
+	for _, c := range []string{"!!!", encodeB64("no-separator"), encodeB64("x|u1"), encodeB64("1|")} {
+		_, err = r2.ListOrders(context.Background(), OrderFilter{Cursor: c})
+		require.ErrorIs(t, err, ErrBadCursor, c)
+	}
+
+	m3, _ := pgxmock.NewPool()
+	defer m3.Close()
+	m3.ExpectQuery(`LIMIT \$1`).WithArgs(2).WillReturnRows(pgxmock.NewRows([]string{"order_uid"}).AddRow("u1"))
+	r3 := &OrdersRepo{Pool: m3, qTimeout: 2 * time.Second, txTimeout: 5 * time.Second}
+	_, err = r3.ListOrders(context.Background(), OrderFilter{Limit: 1})
+	require.ErrorContains(t, err, "listOrders scan")
+}
+
+func encodeB64(s string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(s))
+}
+
+func Test_FindOrderUIDs_Keys(t *testing.T) {
+	cases := []struct {
+		key   LookupKey
+		value string
+		arg   any
+		re    string
+	}{
+		{ByTrackNumber, "TN1", "TN1", `FROM orders WHERE track_number = \$1`},
+		{ByCustomer, "c1", "c1", `FROM orders WHERE customer_id = \$1`},
+		{ByTransaction, "tx1", "tx1", `WHERE p\.transaction_id = \$1`},
+		{ByRID, "rid1", "rid1", `i\.rid = \$1`},
+		{ByChrtID, "9934930", int64(9934930), `i\.chrt_id = \$1`},
+	}
+	for _, tc := range cases {
+		m, _ := pgxmock.NewPool()
+		m.ExpectQuery(tc.re).WithArgs(tc.arg, 5).
+			WillReturnRows(pgxmock.NewRows([]string{"order_uid"}).AddRow("u2").AddRow("u1"))
+		r := &OrdersRepo{Pool: m, qTimeout: 2 * time.Second, txTimeout: 5 * time.Second}
+		uids, err := r.FindOrderUIDs(context.Background(), tc.key, tc.value, 5)
+		require.NoError(t, err, tc.key)
+		require.Equal(t, []string{"u2", "u1"}, uids, tc.key)
+		require.NoError(t, m.ExpectationsWereMet(), tc.key)
+		m.Close()
+	}
+}
+
+func Test_FindOrderUIDs_BadInput_And_Errors(t *testing.T) {
+	r0 := &OrdersRepo{}
+	for _, tc := range []struct {
+		key   LookupKey
+		value string
+	}{
+		{"nope", "x"},
+		{ByTrackNumber, ""},
+		{ByRID, strings.Repeat("r", maxLookupLen+1)},
+		{ByChrtID, "abc"},
+	} {
+		_, err := r0.FindOrderUIDs(context.Background(), tc.key, tc.value, 1)
+		require.ErrorIs(t, err, ErrBadLookup)
+	}
+
+	m1, _ := pgxmock.NewPool()
+	defer m1.Close()
+	m1.ExpectQuery(`track_number = \$1`).WithArgs("TN", defaultPageSize).WillReturnError(errors.New("boom"))
+	r1 := &OrdersRepo{Pool: m1, qTimeout: 2 * time.Second, txTimeout: 5 * time.Second}
+	_, err := r1.FindOrderUIDs(context.Background(), ByTrackNumber, "TN", 0)
+	require.ErrorContains(t, err, "find track_number query")
+	require.ErrorContains(t, err, "boom")
+
+	m2, _ := pgxmock.NewPool()
+	defer m2.Close()
+	rows := pgxmock.NewRows([]string{"order_uid"})
+	rows.RowError(0, errors.New("scan-fail"))
+	m2.ExpectQuery(`customer_id = \$1`).WithArgs("c1", maxPageSize).WillReturnRows(rows)
+	r2 := &OrdersRepo{Pool: m2, qTimeout: 2 * time.Second, txTimeout: 5 * time.Second}
+	_, err = r2.FindOrderUIDs(context.Background(), ByCustomer, "c1", 1000)
+	require.ErrorContains(t, err, "find customer_id rows")
+}
+
+func Test_searchText_Weights(t *testing.T) {
+	contacts, address, goods := searchText(sampleOrder())
+	require.Equal(t, "Name n@example.com +100000", contacts)
+	require.Equal(t, "City Addr", address)
+	require.Equal(t, "Item1 Brand Item2 Brand", goods)
+
+	_, _, goods = searchText(Order{})
+	require.Empty(t, goods)
+}
+
+func Test_Upsert_QueuesSearchDocument(t *testing.T) {
+	fdb := &fakeDBBatch{}
+	r := &OrdersRepo{Pool: fdb, qTimeout: 2 * time.Second, txTimeout: 5 * time.Second}
+	require.NoError(t, r.UpsertOrder(context.Background(), sampleOrder()))
+
+	q := fdb.tx.batch.QueuedQueries[0]
+	require.Equal(t, qUpsertOrder, q.SQL)
+	require.Len(t, q.Arguments, 14)
+	require.Equal(t, []any{"Name n@example.com +100000", "City Addr", "Item1 Brand Item2 Brand"}, q.Arguments[11:])
+
+	fdb2 := &fakeDBBatch{}
+	r2 := &OrdersRepo{Pool: fdb2, qTimeout: 2 * time.Second, txTimeout: 5 * time.Second}
+	require.NoError(t, r2.UpsertOrders(context.Background(), []Order{sampleOrder()}))
+	require.Len(t, fdb2.tx.batch.QueuedQueries[0].Arguments, 14)
+}
+
+func Test_SearchOrders(t *testing.T) {
+	m, _ := pgxmock.NewPool()
+	defer m.Close()
+	cols := []string{"order_uid", "track_number", "customer_id", "delivery_service", "date_created",
+		"currency", "provider", "amount", "rank", "headline"}
+	m.ExpectQuery(`websearch_to_tsquery\('simple', \$1\)`).
+		WithArgs("moscow", 5).
+		WillReturnRows(pgxmock.NewRows(cols).
+			AddRow("u1", "TN", "c1", "meest", tNow(), "RUB", "wbpay", int32(10), float32(0.6), "<mark>Moscow</mark> Lenina 1"))
+	r := &OrdersRepo{Pool: m, qTimeout: 2 * time.Second, txTimeout: 5 * time.Second}
+	hits, err := r.SearchOrders(context.Background(), "  moscow ", 5)
+	require.NoError(t, err)
+	require.Len(t, hits, 1)
+	require.Equal(t, "u1", hits[0].OrderUID)
+	require.Equal(t, float32(0.6), hits[0].Rank)
+	require.Contains(t, hits[0].Headline, "<mark>")
+	require.NoError(t, m.ExpectationsWereMet())
+
+	_, err = r.SearchOrders(context.Background(), "   ", 5)
+	require.ErrorIs(t, err, ErrBadQuery)
+	_, err = r.SearchOrders(context.Background(), strings.Repeat("q", maxSearchLen+1), 5)
+	require.ErrorIs(t, err, ErrBadQuery)
+
+	m2, _ := pgxmock.NewPool()
+	defer m2.Close()
+	m2.ExpectQuery(`websearch_to_tsquery`).WithArgs("x", defaultPageSize).WillReturnError(errors.New("boom"))
+	r2 := &OrdersRepo{Pool: m2, qTimeout: 2 * time.Second, txTimeout: 5 * time.Second}
+	_, err = r2.SearchOrders(context.Background(), "x", 0)
+	require.ErrorContains(t, err, "search query: boom")
+}
+
+var (
+	spansOnce sync.Once
+	spans     *tracetest.SpanRecorder
+)
+
+func recordSpans() *tracetest.SpanRecorder {
+	spansOnce.Do(func() {
+		spans = tracetest.NewSpanRecorder()
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)))
+	})
+	return spans
+}
+
+func Test_Tracing_ChildSpansPerQuery(t *testing.T) {
+	rec := recordSpans()
+
+	m, _ := pgxmock.NewPool()
+	defer m.Close()
+	m.ExpectQuery(regexp.QuoteMeta(qOrders)).WithArgs([]string{"nope"}).WillReturnRows(orderRows())
+	m.ExpectQuery(regexp.QuoteMeta(qByTrackNumber)).WithArgs("TRK", 10).WillReturnError(errors.New("db down"))
+	r := &OrdersRepo{Pool: m, qTimeout: 2 * time.Second, txTimeout: 5 * time.Second}
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "parent")
+	_, err := r.GetOrder(ctx, "nope")
+	require.ErrorIs(t, err, ErrNotFound)
+	_, err = r.FindOrderUIDs(ctx, ByTrackNumber, "TRK", 10)
+	require.Error(t, err)
+	parent.End()
+	require.NoError(t, m.ExpectationsWereMet())
+
+	var ended []sdktrace.ReadOnlySpan
+	for _, s := range rec.Ended() {
+		if s.SpanContext().TraceID() == parent.SpanContext().TraceID() {
+			ended = append(ended, s)
+		}
+	}
+	require.Len(t, ended, 3)
+	get, find := ended[0], ended[1]
+	require.Equal(t, "repo.GetOrders", get.Name())
+	require.Equal(t, "repo.FindOrderUIDs", find.Name())
+	for _, s := range []sdktrace.ReadOnlySpan{get, find} {
+		require.Equal(t, parent.SpanContext().SpanID(), s.Parent().SpanID())
+	}
+	require.Equal(t, codes.Unset, get.Status().Code, "not found — не ошибка")
+	require.Equal(t, codes.Error, find.Status().Code)
+	require.Contains(t, get.Attributes(), attribute.Int("orders.count", 1))
+	require.Contains(t, find.Attributes(), attribute.String("lookup.key", "track_number"))
+}
+
+func Test_IsPermanent(t *testing.T) {
+	require.True(t, IsPermanent(ErrBadUID))
+	require.True(t, IsPermanent(fmt.Errorf("upsert: %w", ErrInconsistent)))
+	require.True(t, IsPermanent(fmt.Errorf("exec: %w", &pgconn.PgError{Code: "22021"})), "NUL в тексте")
+	require.True(t, IsPermanent(&pgconn.PgError{Code: "23505"}))
+	require.False(t, IsPermanent(&pgconn.PgError{Code: "40001"}), "serialization failure — можно повторить")
+	require.False(t, IsPermanent(errors.New("conn reset")))
+	require.False(t, IsPermanent(context.DeadlineExceeded))
+}
@@ -1,28 +1,76 @@
 package repo
 
 const (
-	qOrder = `SELECT order_uid, track_number, entry, locale, internal_signature, customer_id,
-                     delivery_service, shardkey, sm_id, date_created, oof_shard
-              FROM orders WHERE order_uid = $1`
+	qOrders = `SELECT o.order_uid, o.track_number, o.entry, o.locale, o.internal_signature, o.customer_id,
+       o.delivery_service, o.shardkey, o.sm_id, o.date_created, o.oof_shard,
+       (SELECT json_build_object(
+          'transaction', p.transaction_id, 'request_id', p.request_id, 'currency', p.currency,
+          'provider', p.provider, 'amount', p.amount, 'payment_dt', p.payment_dt, 'bank', p.bank,
+          'delivery_cost', p.delivery_cost, 'goods_total', p.goods_total, 'custom_fee', p.custom_fee)
+        FROM order_payment p WHERE p.order_uid = o.order_uid),
+       (SELECT json_build_object(
+          'name', d.name, 'phone', d.phone, 'zip', d.zip, 'city', d.city,
+          'address', d.address, 'region', d.region, 'email', d.email)
+        FROM order_delivery d WHERE d.order_uid = o.order_uid),
+       COALESCE((SELECT json_agg(json_build_object(
+          'chrt_id', i.chrt_id, 'track_number', i.track_number, 'price', i.price, 'rid', i.rid,
+          'name', i.name, 'sale', i.sale, 'size', i.size, 'total_price', i.total_price,
+          'nm_id', i.nm_id, 'brand', i.brand, 'status', i.status) ORDER BY i.id)
+        FROM order_items i WHERE i.order_uid = o.order_uid), '[]'::json)
+FROM orders o
+WHERE o.order_uid = ANY($1)
+ORDER BY o.date_created DESC, o.order_uid DESC`
 
-	qDelivery = `SELECT name, phone, zip, city, address, region, email
-                 FROM order_delivery WHERE order_uid = $1`
+	qListOrders = `SELECT o.order_uid, o.track_number, o.customer_id, o.delivery_service, o.date_created,
+       p.currency, p.provider, p.amount
+FROM orders o
+JOIN order_payment p ON p.order_uid = o.order_uid`
+)
+
+const (
+	qByTrackNumber = `SELECT order_uid FROM orders WHERE track_number = $1
+ORDER BY date_created DESC, order_uid DESC LIMIT $2`
+
+	qByCustomer = `SELECT order_uid FROM orders WHERE customer_id = $1
+ORDER BY date_created DESC, order_uid DESC LIMIT $2`
+
+	qByTransaction = `SELECT o.order_uid FROM orders o
+JOIN order_payment p ON p.order_uid = o.order_uid
+WHERE p.transaction_id = $1
+ORDER BY o.date_created DESC, o.order_uid DESC LIMIT $2`
 
-	qPayment = `SELECT transaction_id, request_id, currency, provider, amount, payment_dt,
-                       bank, delivery_cost, goods_total, custom_fee
-                FROM order_payment WHERE order_uid = $1`
+	qByRID = `SELECT o.order_uid FROM orders o
+WHERE EXISTS (SELECT 1 FROM order_items i WHERE i.order_uid = o.order_uid AND i.rid = $1)
+ORDER BY o.date_created DESC, o.order_uid DESC LIMIT $2`
 
-	qItems = `SELECT id, chrt_id, track_number, price, rid, name, sale, size,
-                     total_price, nm_id, brand, status
-              FROM order_items WHERE order_uid = $1 ORDER BY id`
+	qByChrtID = `SELECT o.order_uid FROM orders o
+WHERE EXISTS (SELECT 1 FROM order_items i WHERE i.order_uid = o.order_uid AND i.chrt_id = $1)
+ORDER BY o.date_created DESC, o.order_uid DESC LIMIT $2`
+
+	qSearchOrders = `SELECT o.order_uid, o.track_number, o.customer_id, o.delivery_service, o.date_created,
+       p.currency, p.provider, p.amount,
+       ts_rank(o.search, q.q) AS rank,
+       ts_headline('simple',
+         concat_ws(' ', d.name, d.email, d.phone, d.city, d.address,
+           (SELECT string_agg(i.name || ' ' || i.brand, ' ' ORDER BY i.id) FROM order_items i WHERE i.order_uid = o.order_uid)),
+         q.q, 'StartSel=<mark>, StopSel=</mark>, MaxFragments=3, MaxWords=12, MinWords=3') AS headline
+FROM websearch_to_tsquery('simple', $1) AS q(q)
+JOIN orders o ON o.search @@ q.q
+JOIN order_payment p ON p.order_uid = o.order_uid
+JOIN order_delivery d ON d.order_uid = o.order_uid
+ORDER BY rank DESC, o.date_created DESC, o.order_uid DESC
+LIMIT $2`
 )
 
 const (
 	qUpsertOrder = `
 INSERT INTO orders (
   order_uid, track_number, entry, locale, internal_signature, customer_id,
-  delivery_service, shardkey, sm_id, date_created, oof_shard
-) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)
+  delivery_service, shardkey, sm_id, date_created, oof_shard, search
+) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,
+  setweight(to_tsvector('simple', $12), 'A') ||
+  setweight(to_tsvector('simple', $13), 'B') ||
+  setweight(to_tsvector('simple', $14), 'C'))
 ON CONFLICT (order_uid) DO UPDATE SET
   track_number=EXCLUDED.track_number,
   entry=EXCLUDED.entry,
@@ -33,7 +81,8 @@ ON CONFLICT (order_uid) DO UPDATE SET
   shardkey=EXCLUDED.shardkey,
   sm_id=EXCLUDED.sm_id,
   date_created=EXCLUDED.date_created,
-  oof_shard=EXCLUDED.oof_shard
+  oof_shard=EXCLUDED.oof_shard,
+  search=EXCLUDED.search
 `
 
 	qUpsertPayment = `
@@ -77,3 +126,10 @@ INSERT INTO order_items (
 ) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12)
 `
 )
+
+const qDeleteItemsMany = `DELETE FROM order_items WHERE order_uid = ANY($1)`
+
+var itemColumns = []string{
+	"order_uid", "chrt_id", "track_number", "price", "rid", "name", "sale", "size",
+	"total_price", "nm_id", "brand", "status",
+}
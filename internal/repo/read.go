@@ -2,90 +2,68 @@ package repo
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 
 	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
 )
 
-func (r *OrdersRepo) getOrderHeader(ctx context.Context, uid string) (Order, error) {
-	var o Order
+func (r *OrdersRepo) getOrders(ctx context.Context, uids []string) (_ []Order, err error) {
+	ctx, span := startSpan(ctx, "GetOrders", attribute.Int("orders.count", len(uids)))
+	defer func() { endSpan(span, err) }()
+
 	ctxT, cancel := r.withQ(ctx)
 	defer cancel()
 
-	err := r.Pool.QueryRow(ctxT, qOrder, uid).Scan(
-		&o.OrderUID, &o.TrackNumber, &o.Entry, &o.Locale, &o.InternalSignature,
-		&o.CustomerID, &o.DeliveryService, &o.ShardKey, &o.SMID, &o.DateCreated, &o.OofShard,
-	)
-	if errorsIsNoRows(err) {
-		return Order{}, ErrNotFound
-	}
+	rows, err := r.Pool.Query(ctxT, qOrders, uids)
 	if err != nil {
-		return Order{}, fmt.Errorf("getOrderHeader: %w", err)
+		return nil, fmt.Errorf("getOrders query: %w", err)
 	}
-	return o, nil
-}
-
-func (r *OrdersRepo) getDelivery(ctx context.Context, uid string) (Delivery, error) {
-	var d Delivery
-	ctxT, cancel := r.withQ(ctx)
-	defer cancel()
+	defer rows.Close()
 
-	err := r.Pool.QueryRow(ctxT, qDelivery, uid).Scan(
-		&d.Name, &d.Phone, &d.Zip, &d.City, &d.Address, &d.Region, &d.Email,
-	)
-	if errorsIsNoRows(err) {
-		return Delivery{}, fmt.Errorf("%w: delivery missing", ErrInconsistent)
+	out := make([]Order, 0, len(uids))
+	for rows.Next() {
+		o, err := scanOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, o)
 	}
-	if err != nil {
-		return Delivery{}, fmt.Errorf("getDelivery: %w", err)
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("getOrders rows: %w", err)
 	}
-	return d, nil
+	return out, nil
 }
 
-func (r *OrdersRepo) getPayment(ctx context.Context, uid string) (Payment, error) {
-	var p Payment
-	ctxT, cancel := r.withQ(ctx)
-	defer cancel()
-
-	err := r.Pool.QueryRow(ctxT, qPayment, uid).Scan(
-		&p.TransactionID, &p.RequestID, &p.Currency, &p.Provider, &p.Amount,
-		&p.PaymentDT, &p.Bank, &p.DeliveryCost, &p.GoodsTotal, &p.CustomFee,
+func scanOrder(row pgx.Row) (Order, error) {
+	var (
+		o                        Order
+		payment, delivery, items []byte
 	)
-	if errorsIsNoRows(err) {
-		return Payment{}, fmt.Errorf("%w: payment missing", ErrInconsistent)
-	}
-	if err != nil {
-		return Payment{}, fmt.Errorf("getPayment: %w", err)
+	if err := row.Scan(
+		&o.OrderUID, &o.TrackNumber, &o.Entry, &o.Locale, &o.InternalSignature,
+		&o.CustomerID, &o.DeliveryService, &o.ShardKey, &o.SMID, &o.DateCreated, &o.OofShard,
+		&payment, &delivery, &items,
+	); err != nil {
+		return Order{}, fmt.Errorf("getOrders scan: %w", err)
 	}
-	return p, nil
-}
 
-func (r *OrdersRepo) getItems(ctx context.Context, uid string) ([]Item, error) {
-	ctxT, cancel := r.withQ(ctx)
-	defer cancel()
-
-	rows, err := r.Pool.Query(ctxT, qItems, uid)
-	if err != nil {
-		return nil, fmt.Errorf("getItems query: %w", err)
+	if payment == nil {
+		return Order{}, fmt.Errorf("%w: payment missing for %s", ErrInconsistent, o.OrderUID)
 	}
-	defer rows.Close()
-
-	items := make([]Item, 0, defaultItemsCap)
-	for rows.Next() {
-		var it Item
-		var id int64
-		if err := rows.Scan(
-			&id, &it.ChrtID, &it.TrackNumber, &it.Price, &it.RID, &it.Name,
-			&it.Sale, &it.Size, &it.TotalPrice, &it.NmID, &it.Brand, &it.Status,
-		); err != nil {
-			return nil, fmt.Errorf("getItems scan: %w", err)
-		}
-		items = append(items, it)
+	if delivery == nil {
+		return Order{}, fmt.Errorf("%w: delivery missing for %s", ErrInconsistent, o.OrderUID)
 	}
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("getItems rows: %w", err)
+	if err := json.Unmarshal(payment, &o.Payment); err != nil {
+		return Order{}, fmt.Errorf("getOrders payment %s: %w", o.OrderUID, err)
 	}
-	return items, nil
+	if err := json.Unmarshal(delivery, &o.Delivery); err != nil {
+		return Order{}, fmt.Errorf("getOrders delivery %s: %w", o.OrderUID, err)
+	}
+	o.Items = make([]Item, 0, defaultItemsCap)
+	if err := json.Unmarshal(items, &o.Items); err != nil {
+		return Order{}, fmt.Errorf("getOrders items %s: %w", o.OrderUID, err)
+	}
+	return o, nil
 }
-
-func errorsIsNoRows(err error) bool { return err == pgx.ErrNoRows }
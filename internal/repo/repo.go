@@ -49,26 +49,35 @@ func (r *OrdersRepo) GetOrder(ctx context.Context, uid string) (Order, error) {
 		return Order{}, ErrBadUID
 	}
 
-	o, err := r.getOrderHeader(ctx, uid)
+	orders, err := r.getOrders(ctx, []string{uid})
 	if err != nil {
 		return Order{}, err
 	}
-	if o.Payment, err = r.getPayment(ctx, uid); err != nil {
-		return Order{}, err
+	if len(orders) == 0 {
+		return Order{}, ErrNotFound
 	}
-	if o.Delivery, err = r.getDelivery(ctx, uid); err != nil {
-		return Order{}, err
+	return orders[0], nil
+}
+
+func (r *OrdersRepo) GetOrders(ctx context.Context, uids []string) ([]Order, error) {
+	if len(uids) == 0 {
+		return []Order{}, nil
 	}
-	if o.Items, err = r.getItems(ctx, uid); err != nil {
-		return Order{}, err
+	for _, uid := range uids {
+		if uid == "" || len(uid) > maxUIDLen {
+			return nil, fmt.Errorf("%w: %q", ErrBadUID, uid)
+		}
 	}
-	return o, nil
+	return r.getOrders(ctx, uids)
 }
 
-func (r *OrdersRepo) ListRecentOrderUIDs(ctx context.Context, limit int) ([]string, error) {
+func (r *OrdersRepo) ListRecentOrderUIDs(ctx context.Context, limit int) (_ []string, err error) {
 	if limit <= 0 {
 		return []string{}, nil
 	}
+	ctx, span := startSpan(ctx, "ListRecentOrderUIDs")
+	defer func() { endSpan(span, err) }()
+
 	ctxT, cancel := r.withQ(ctx)
 	defer cancel()
 
@@ -100,6 +109,13 @@ func (r *OrdersRepo) UpsertOrder(ctx context.Context, o Order) (err error) {
 	return r.upsertOrderBatch(ctx, o)
 }
 
+func (r *OrdersRepo) UpsertOrders(ctx context.Context, orders []Order) error {
+	if len(orders) == 0 {
+		return nil
+	}
+	return r.upsertOrdersCopy(ctx, orders)
+}
+
 func (r *OrdersRepo) Ping(ctx context.Context) error {
 	ctxT, cancel := r.withQ(ctx)
 	defer cancel()
@@ -0,0 +1,71 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+const maxSearchLen = 200
+
+type SearchHit struct {
+	OrderSummary
+	Rank     float32 `json:"rank"`
+	Headline string  `json:"headline"`
+}
+
+func (r *OrdersRepo) SearchOrders(ctx context.Context, q string, limit int) (_ []SearchHit, err error) {
+	ctx, span := startSpan(ctx, "SearchOrders")
+	defer func() { endSpan(span, err) }()
+
+	q = strings.TrimSpace(q)
+	if q == "" || len(q) > maxSearchLen {
+		return nil, ErrBadQuery
+	}
+	if limit <= 0 {
+		limit = defaultPageSize
+	}
+	limit = min(limit, maxPageSize)
+
+	ctxT, cancel := r.withQ(ctx)
+	defer cancel()
+
+	rows, err := r.Pool.Query(ctxT, qSearchOrders, q, limit)
+	if err != nil {
+		return nil, fmt.Errorf("search query: %w", err)
+	}
+	defer rows.Close()
+
+	hits := make([]SearchHit, 0, limit)
+	for rows.Next() {
+		var h SearchHit
+		if err := rows.Scan(
+			&h.OrderUID, &h.TrackNumber, &h.CustomerID, &h.DeliveryService, &h.DateCreated,
+			&h.Currency, &h.Provider, &h.Amount, &h.Rank, &h.Headline,
+		); err != nil {
+			return nil, fmt.Errorf("search scan: %w", err)
+		}
+		hits = append(hits, h)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("search rows: %w", err)
+	}
+	return hits, nil
+}
+
+func searchText(o Order) (contacts, address, goods string) {
+	d := o.Delivery
+	contacts = strings.Join([]string{d.Name, d.Email, d.Phone}, " ")
+	address = strings.Join([]string{d.City, d.Address}, " ")
+
+	var sb strings.Builder
+	for i, it := range o.Items {
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(it.Name)
+		sb.WriteByte(' ')
+		sb.WriteString(it.Brand)
+	}
+	return contacts, address, sb.String()
+}
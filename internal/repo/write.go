@@ -5,9 +5,13 @@ import (
 	"fmt"
 
 	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 func (r *OrdersRepo) upsertOrderBatch(ctx context.Context, o Order) (err error) {
+	ctx, span := startSpan(ctx, "UpsertOrder")
+	defer func() { endSpan(span, err) }()
+
 	if o.OrderUID == "" || len(o.OrderUID) > maxUIDLen {
 		return ErrBadUID
 	}
@@ -31,10 +35,13 @@ func (r *OrdersRepo) upsertOrderBatch(ctx context.Context, o Order) (err error)
 		}
 	}()
 
+	contacts, address, goods := searchText(o)
+
 	var b pgx.Batch
 	b.Queue(qUpsertOrder,
 		o.OrderUID, o.TrackNumber, o.Entry, o.Locale, o.InternalSignature, o.CustomerID,
 		o.DeliveryService, o.ShardKey, o.SMID, o.DateCreated, o.OofShard,
+		contacts, address, goods,
 	)
 	b.Queue(qUpsertPayment,
 		o.OrderUID, o.Payment.TransactionID, o.Payment.RequestID, o.Payment.Currency,
@@ -75,3 +82,115 @@ func (r *OrdersRepo) upsertOrderBatch(ctx context.Context, o Order) (err error)
 
 	return nil
 }
+
+func (r *OrdersRepo) upsertOrdersCopy(ctx context.Context, orders []Order) (err error) {
+	ctx, span := startSpan(ctx, "UpsertOrders", attribute.Int("orders.count", len(orders)))
+	defer func() { endSpan(span, err) }()
+
+	orders = dedupeOrders(orders)
+	uids := make([]string, 0, len(orders))
+	for i := range orders {
+		o := &orders[i]
+		if o.OrderUID == "" || len(o.OrderUID) > maxUIDLen {
+			return fmt.Errorf("%w: %q", ErrBadUID, o.OrderUID)
+		}
+		if o.Payment.Amount < 0 {
+			return fmt.Errorf("%w: negative amount in %s", ErrInconsistent, o.OrderUID)
+		}
+		o.DateCreated = o.DateCreated.UTC()
+		uids = append(uids, o.OrderUID)
+	}
+
+	ctxT, cancel := r.withTx(ctx)
+	defer cancel()
+
+	tx, err := r.Pool.BeginTx(ctxT, pgx.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback(ctxT)
+			panic(p)
+		}
+	}()
+
+	var b pgx.Batch
+	var itemRows [][]any
+	for _, o := range orders {
+		contacts, address, goods := searchText(o)
+		b.Queue(qUpsertOrder,
+			o.OrderUID, o.TrackNumber, o.Entry, o.Locale, o.InternalSignature, o.CustomerID,
+			o.DeliveryService, o.ShardKey, o.SMID, o.DateCreated, o.OofShard,
+			contacts, address, goods,
+		)
+		b.Queue(qUpsertPayment,
+			o.OrderUID, o.Payment.TransactionID, o.Payment.RequestID, o.Payment.Currency,
+			o.Payment.Provider, o.Payment.Amount, o.Payment.PaymentDT, o.Payment.Bank,
+			o.Payment.DeliveryCost, o.Payment.GoodsTotal, o.Payment.CustomFee,
+		)
+		b.Queue(qUpsertDelivery,
+			o.OrderUID, o.Delivery.Name, o.Delivery.Phone, o.Delivery.Zip, o.Delivery.City,
+			o.Delivery.Address, o.Delivery.Region, o.Delivery.Email,
+		)
+		for _, it := range o.Items {
+			itemRows = append(itemRows, []any{
+				o.OrderUID, it.ChrtID, it.TrackNumber, it.Price, it.RID, it.Name,
+				it.Sale, it.Size, it.TotalPrice, it.NmID, it.Brand, it.Status,
+			})
+		}
+	}
+	b.Queue(qDeleteItemsMany, uids)
+
+	br := tx.SendBatch(ctxT, &b)
+
+	steps := 3*len(orders) + 1
+	for i := 0; i < steps; i++ {
+		if _, execErr := br.Exec(); execErr != nil {
+			_ = br.Close()
+			_ = tx.Rollback(ctxT)
+			return fmt.Errorf("batch step %d: %w", i, execErr)
+		}
+	}
+
+	if errClose := br.Close(); errClose != nil {
+		_ = tx.Rollback(ctxT)
+		return fmt.Errorf("batch close: %w", errClose)
+	}
+
+	if len(itemRows) > 0 {
+		n, cErr := tx.CopyFrom(ctxT, pgx.Identifier{"order_items"}, itemColumns, pgx.CopyFromRows(itemRows))
+		if cErr != nil {
+			_ = tx.Rollback(ctxT)
+			return fmt.Errorf("copy items: %w", cErr)
+		}
+		if n != int64(len(itemRows)) {
+			_ = tx.Rollback(ctxT)
+			return fmt.Errorf("%w: copied %d of %d items", ErrInconsistent, n, len(itemRows))
+		}
+	}
+
+	if cErr := tx.Commit(ctxT); cErr != nil {
+		return fmt.Errorf("commit: %w", cErr)
+	}
+
+	return nil
+}
+
+func dedupeOrders(orders []Order) []Order {
+	last := make(map[string]int, len(orders))
+	for i, o := range orders {
+		last[o.OrderUID] = i
+	}
+	if len(last) == len(orders) {
+		return append([]Order(nil), orders...)
+	}
+	out := make([]Order, 0, len(last))
+	for i, o := range orders {
+		if last[o.OrderUID] == i {
+			out = append(out, o)
+		}
+	}
+	return out
+}
@@ -0,0 +1,33 @@
+package tracing_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+
+	"github.com/mrussa/L0/internal/tracing"
+)
+
+func TestSetup(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := tracing.Setup(ctx, tracing.Config{Exporter: "jaeger"})
+	require.ErrorIs(t, err, tracing.ErrUnknownExporter)
+
+	shutdown, err := tracing.Setup(ctx, tracing.Config{Exporter: tracing.ExporterNone})
+	require.NoError(t, err)
+	require.NoError(t, shutdown(ctx))
+	require.ElementsMatch(t, []string{"traceparent", "tracestate", "baggage"}, otel.GetTextMapPropagator().Fields(),
+		"пропагатор W3C ставится даже без экспортера")
+
+	shutdown, err = tracing.Setup(ctx, tracing.Config{Exporter: tracing.ExporterOTLP, Endpoint: "http://127.0.0.1:1", ServiceName: "test"})
+	require.NoError(t, err, "экспортер OTLP подключается лениво")
+	_, span := otel.Tracer("test").Start(ctx, "op")
+	require.True(t, span.SpanContext().IsValid())
+	span.End()
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+	_ = shutdown(cctx)
+}
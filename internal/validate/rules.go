@@ -0,0 +1,149 @@
+package validate
+
+import (
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strings"
+
+	"github.com/mrussa/L0/internal/repo"
+)
+
+const maxOrderUID = 100
+
+var Rules = []Rule{
+	{Name: "required", Check: checkRequired},
+	{Name: "amount", Check: checkAmount},
+	{Name: "currency", Check: checkCurrency},
+	{Name: "goods_total", Check: checkGoodsTotal},
+	{Name: "amount_sum", Check: checkAmountSum},
+	{Name: "item_track", Check: checkItemTrack},
+	{Name: "email", Check: checkEmail},
+	{Name: "phone", Check: checkPhone},
+}
+
+func checkRequired(o *repo.Order, report func(field, msg string)) {
+	switch {
+	case o.OrderUID == "":
+		report("order_uid", "empty")
+	case len(o.OrderUID) > maxOrderUID:
+		report("order_uid", "too long")
+	}
+	if o.TrackNumber == "" {
+		report("track_number", "empty")
+	}
+	if o.Payment.Currency == "" {
+		report("currency", "empty")
+	}
+}
+
+func checkAmount(o *repo.Order, report func(field, msg string)) {
+	p := o.Payment
+	for _, f := range []struct {
+		name string
+		v    int32
+	}{
+		{"amount", p.Amount},
+		{"goods_total", p.GoodsTotal},
+		{"delivery_cost", p.DeliveryCost},
+		{"custom_fee", p.CustomFee},
+	} {
+		if f.v < 0 {
+			report(f.name, "negative")
+		}
+	}
+	for i, it := range o.Items {
+		if it.Price < 0 {
+			report(itemField(i, "price"), "negative")
+		}
+		if it.TotalPrice < 0 {
+			report(itemField(i, "total_price"), "negative")
+		}
+	}
+}
+
+func checkCurrency(o *repo.Order, report func(field, msg string)) {
+	c := o.Payment.Currency
+	if c == "" {
+		return
+	}
+	if !iso4217[c] {
+		report("currency", fmt.Sprintf("%q is not an ISO 4217 code", c))
+	}
+}
+
+func checkGoodsTotal(o *repo.Order, report func(field, msg string)) {
+	var sum int64
+	for _, it := range o.Items {
+		sum += int64(it.TotalPrice)
+	}
+	if sum != int64(o.Payment.GoodsTotal) {
+		report("goods_total", fmt.Sprintf("%d, items total_price sum is %d", o.Payment.GoodsTotal, sum))
+	}
+}
+
+func checkAmountSum(o *repo.Order, report func(field, msg string)) {
+	p := o.Payment
+	want := int64(p.GoodsTotal) + int64(p.DeliveryCost) + int64(p.CustomFee)
+	if int64(p.Amount) != want {
+		report("amount", fmt.Sprintf("%d, goods_total+delivery_cost+custom_fee is %d", p.Amount, want))
+	}
+}
+
+func checkItemTrack(o *repo.Order, report func(field, msg string)) {
+	if o.TrackNumber == "" {
+		return
+	}
+	for i, it := range o.Items {
+		if it.TrackNumber != o.TrackNumber {
+			report(itemField(i, "track_number"), fmt.Sprintf("%q, order track_number is %q", it.TrackNumber, o.TrackNumber))
+		}
+	}
+}
+
+func checkEmail(o *repo.Order, report func(field, msg string)) {
+	e := o.Delivery.Email
+	if e == "" {
+		return
+	}
+	addr, err := mail.ParseAddress(e)
+	if err == nil && addr.Address == e {
+		_, domain, _ := strings.Cut(addr.Address, "@")
+		if strings.Contains(domain, ".") {
+			return
+		}
+	}
+	report("email", fmt.Sprintf("invalid address %q", e))
+}
+
+var phoneRe = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+
+func checkPhone(o *repo.Order, report func(field, msg string)) {
+	p := o.Delivery.Phone
+	if p == "" {
+		return
+	}
+	if !phoneRe.MatchString(p) {
+		report("phone", fmt.Sprintf("invalid number %q, want E.164 like +79001234567", p))
+	}
+}
+
+func itemField(i int, name string) string {
+	return fmt.Sprintf("items[%d].%s", i, name)
+}
+
+var iso4217 = func() map[string]bool {
+	const codes = `AED AFN ALL AMD ANG AOA ARS AUD AWG AZN BAM BBD BDT BGN BHD BIF BMD BND BOB BOV
+BRL BSD BTN BWP BYN BZD CAD CDF CHE CHF CHW CLF CLP CNY COP COU CRC CUC CUP CVE CZK DJF DKK DOP DZD
+EGP ERN ETB EUR FJD FKP GBP GEL GHS GIP GMD GNF GTQ GYD HKD HNL HTG HUF IDR ILS INR IQD IRR ISK JMD
+JOD JPY KES KGS KHR KMF KPW KRW KWD KYD KZT LAK LBP LKR LRD LSL LYD MAD MDL MGA MKD MMK MNT MOP MRU
+MUR MVR MWK MXN MXV MYR MZN NAD NGN NIO NOK NPR NZD OMR PAB PEN PGK PHP PKR PLN PYG QAR RON RSD RUB
+RWF SAR SBD SCR SDG SEK SGD SHP SLE SLL SOS SRD SSP STN SVC SYP SZL THB TJS TMT TND TOP TRY TTD TWD
+TZS UAH UGX USD USN UYI UYU UYW UZS VED VES VND VUV WST XAF XAG XAU XBA XBB XBC XBD XCD XCG XDR XOF
+XPD XPF XPT XSU XTS XUA XXX YER ZAR ZMW ZWG ZWL`
+	m := map[string]bool{}
+	for _, c := range strings.Fields(codes) {
+		m[c] = true
+	}
+	return m
+}()
@@ -0,0 +1,124 @@
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mrussa/L0/internal/repo"
+)
+
+var ErrUnknownRule = errors.New("unknown validation rule")
+
+type Violation struct {
+	Rule    string `json:"rule"`
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (v Violation) String() string { return "field " + v.Field + ": " + v.Message }
+
+type Error struct {
+	Violations []Violation
+}
+
+func (e *Error) Error() string {
+	return strings.Join(e.Strings(), "; ")
+}
+
+func (e *Error) Strings() []string {
+	out := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		out[i] = v.String()
+	}
+	return out
+}
+
+type Rule struct {
+	Name  string
+	Check func(o *repo.Order, report func(field, msg string))
+}
+
+type Validator struct {
+	rules []Rule
+}
+
+func New(rules ...Rule) *Validator {
+	return &Validator{rules: rules}
+}
+
+func Parse(spec string) (*Validator, error) {
+	var enabled []string
+	for _, tok := range strings.Split(spec, ",") {
+		tok = strings.TrimSpace(tok)
+		switch {
+		case tok == "":
+		case tok == "all":
+			for _, r := range Rules {
+				if !slices.Contains(enabled, r.Name) {
+					enabled = append(enabled, r.Name)
+				}
+			}
+		case strings.HasPrefix(tok, "-"):
+			name := tok[1:]
+			if _, ok := lookup(name); !ok {
+				return nil, fmt.Errorf("%w: %q", ErrUnknownRule, name)
+			}
+			enabled = slices.DeleteFunc(enabled, func(n string) bool { return n == name })
+		default:
+			if _, ok := lookup(tok); !ok {
+				return nil, fmt.Errorf("%w: %q", ErrUnknownRule, tok)
+			}
+			if !slices.Contains(enabled, tok) {
+				enabled = append(enabled, tok)
+			}
+		}
+	}
+
+	v := &Validator{}
+	for _, r := range Rules {
+		if slices.Contains(enabled, r.Name) {
+			v.rules = append(v.rules, r)
+		}
+	}
+	return v, nil
+}
+
+func MustParse(spec string) *Validator {
+	v, err := Parse(spec)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+func (v *Validator) Names() []string {
+	names := make([]string, len(v.rules))
+	for i, r := range v.rules {
+		names[i] = r.Name
+	}
+	return names
+}
+
+func (v *Validator) Validate(o *repo.Order) error {
+	var out []Violation
+	for _, r := range v.rules {
+		r.Check(o, func(field, msg string) {
+			out = append(out, Violation{Rule: r.Name, Field: field, Message: msg})
+		})
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return &Error{Violations: out}
+}
+
+func lookup(name string) (Rule, bool) {
+	for _, r := range Rules {
+		if r.Name == name {
+			return r, true
+		}
+	}
+	return Rule{}, false
+}
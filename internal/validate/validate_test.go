@@ -0,0 +1,130 @@
+package validate_test
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrussa/L0/internal/ordergen"
+	"github.com/mrussa/L0/internal/repo"
+	"github.com/mrussa/L0/internal/validate"
+)
+
+func fixture(t *testing.T) repo.Order {
+	t.Helper()
+	b, err := os.ReadFile("../../fixtures/model.json")
+	require.NoError(t, err)
+	var o repo.Order
+	require.NoError(t, json.Unmarshal(b, &o))
+	return o
+}
+
+func TestValidate_FixtureAndGeneratedOrdersPass(t *testing.T) {
+	v := validate.MustParse("all")
+	o := fixture(t)
+	require.NoError(t, v.Validate(&o))
+
+	g := ordergen.New(1)
+	for i := 0; i < 200; i++ {
+		o := g.Order(i)
+		require.NoError(t, v.Validate(&o), "order %d", i)
+	}
+}
+
+func TestValidate_CollectsAllViolations(t *testing.T) {
+	o := fixture(t)
+	o.Payment.Currency = "RUR"
+	o.Payment.GoodsTotal = 300
+	o.Items[0].TrackNumber = "OTHER"
+	o.Delivery.Email = "not-an-email"
+	o.Delivery.Phone = "89001234567"
+
+	err := validate.MustParse("all").Validate(&o)
+	var ve *validate.Error
+	require.ErrorAs(t, err, &ve)
+	require.Equal(t, []validate.Violation{
+		{Rule: "currency", Field: "currency", Message: `"RUR" is not an ISO 4217 code`},
+		{Rule: "goods_total", Field: "goods_total", Message: "300, items total_price sum is 317"},
+		{Rule: "amount_sum", Field: "amount", Message: "1817, goods_total+delivery_cost+custom_fee is 1800"},
+		{Rule: "item_track", Field: "items[0].track_number", Message: `"OTHER", order track_number is "WBILMTESTTRACK"`},
+		{Rule: "email", Field: "email", Message: `invalid address "not-an-email"`},
+		{Rule: "phone", Field: "phone", Message: `invalid number "89001234567", want E.164 like +79001234567`},
+	}, ve.Violations)
+	require.Equal(t, `field currency: "RUR" is not an ISO 4217 code`, ve.Strings()[0])
+}
+
+func TestValidate_SingleViolationMessage(t *testing.T) {
+	o := fixture(t)
+	o.OrderUID = ""
+	require.EqualError(t, validate.MustParse("required").Validate(&o), "field order_uid: empty")
+
+	o.TrackNumber = ""
+	require.EqualError(t, validate.MustParse("required").Validate(&o),
+		"field order_uid: empty; field track_number: empty")
+}
+
+func TestValidate_AmountRejectsNegatives(t *testing.T) {
+	o := fixture(t)
+	o.Payment.DeliveryCost = -1
+	o.Items[0].Price = -5
+
+	var ve *validate.Error
+	require.ErrorAs(t, validate.MustParse("amount").Validate(&o), &ve)
+	require.Equal(t, []string{"field delivery_cost: negative", "field items[0].price: negative"}, ve.Strings())
+}
+
+func TestValidate_EmailAndPhoneFormats(t *testing.T) {
+	v := validate.MustParse("email,phone")
+	cases := []struct {
+		email, phone string
+		ok           bool
+	}{
+		{"", "", true},
+		{"a.b+tag@example.co.uk", "+79001234567", true},
+		{"user@localhost", "", false},
+		{"Name <a@example.com>", "", false},
+		{"", "+7 900 123", false},
+		{"", "+0123456789", false},
+		{"", "+1234567890123456", false},
+	}
+	for _, tc := range cases {
+		o := fixture(t)
+		o.Delivery.Email, o.Delivery.Phone = tc.email, tc.phone
+		err := v.Validate(&o)
+		if tc.ok {
+			require.NoError(t, err, "%q %q", tc.email, tc.phone)
+		} else {
+			require.Error(t, err, "%q %q", tc.email, tc.phone)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	all := []string{"required", "amount", "currency", "goods_total", "amount_sum", "item_track", "email", "phone"}
+
+	v, err := validate.Parse("all")
+	require.NoError(t, err)
+	require.Equal(t, all, v.Names())
+
+	v, err = validate.Parse(" all , -phone,-email ")
+	require.NoError(t, err)
+	require.Equal(t, all[:6], v.Names())
+
+	v, err = validate.Parse("currency,required,currency")
+	require.NoError(t, err)
+	require.Equal(t, []string{"required", "currency"}, v.Names(), "порядок правил фиксирован")
+
+	v, err = validate.Parse("")
+	require.NoError(t, err)
+	require.Empty(t, v.Names())
+	o := repo.Order{}
+	require.NoError(t, v.Validate(&o))
+
+	_, err = validate.Parse("all,-nope")
+	require.ErrorIs(t, err, validate.ErrUnknownRule)
+	_, err = validate.Parse("phones")
+	require.ErrorIs(t, err, validate.ErrUnknownRule)
+	require.EqualError(t, err, `unknown validation rule: "phones"`)
+}
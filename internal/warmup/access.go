@@ -0,0 +1,135 @@
+package warmup
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+const (
+	accessLogVersion    = 1
+	defaultMaxAccessUID = 100000
+)
+
+var ErrAccessLogVersion = errors.New("unsupported access log version")
+
+type AccessLog struct {
+	mu      sync.Mutex
+	counts  map[string]uint64
+	maxUIDs int
+}
+
+type accessLogFile struct {
+	Version int               `json:"version"`
+	Counts  map[string]uint64 `json:"counts"`
+}
+
+func NewAccessLog(maxUIDs int) *AccessLog {
+	if maxUIDs <= 0 {
+		maxUIDs = defaultMaxAccessUID
+	}
+	return &AccessLog{counts: make(map[string]uint64), maxUIDs: maxUIDs}
+}
+
+func (l *AccessLog) Record(uid string) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.counts[uid]; !ok && len(l.counts) >= l.maxUIDs {
+		l.decay()
+	}
+	l.counts[uid]++
+}
+
+func (l *AccessLog) decay() {
+	for uid, n := range l.counts {
+		if n /= 2; n == 0 {
+			delete(l.counts, uid)
+		} else {
+			l.counts[uid] = n
+		}
+	}
+}
+
+func (l *AccessLog) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.counts)
+}
+
+func (l *AccessLog) Top(n int) []string {
+	l.mu.Lock()
+	uids := make([]string, 0, len(l.counts))
+	for uid := range l.counts {
+		uids = append(uids, uid)
+	}
+	sort.Slice(uids, func(i, j int) bool {
+		ci, cj := l.counts[uids[i]], l.counts[uids[j]]
+		if ci != cj {
+			return ci > cj
+		}
+		return uids[i] < uids[j]
+	})
+	l.mu.Unlock()
+
+	if n < len(uids) {
+		uids = uids[:max(n, 0)]
+	}
+	return uids
+}
+
+func (l *AccessLog) Save(path string) error {
+	l.mu.Lock()
+	data, err := json.Marshal(accessLogFile{Version: accessLogVersion, Counts: l.counts})
+	l.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("access log encode: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("access log create: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("access log write: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("access log close: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("access log rename: %w", err)
+	}
+	return nil
+}
+
+func (l *AccessLog) Load(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("access log open: %w", err)
+	}
+	var f accessLogFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return fmt.Errorf("access log decode: %w", err)
+	}
+	if f.Version != accessLogVersion {
+		return fmt.Errorf("%w: got %d, want %d", ErrAccessLogVersion, f.Version, accessLogVersion)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for uid, n := range f.Counts {
+		if _, ok := l.counts[uid]; !ok && len(l.counts) >= l.maxUIDs {
+			continue
+		}
+		l.counts[uid] += n
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package warmup
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+type Source interface {
+	UIDs(ctx context.Context, limit int) ([]string, error)
+}
+
+type SourceFunc func(ctx context.Context, limit int) ([]string, error)
+
+func (f SourceFunc) UIDs(ctx context.Context, limit int) ([]string, error) {
+	return f(ctx, limit)
+}
+
+type RecentLister interface {
+	ListRecentOrderUIDs(ctx context.Context, limit int) ([]string, error)
+}
+
+func Recent(r RecentLister) Source {
+	return SourceFunc(r.ListRecentOrderUIDs)
+}
+
+func Frequent(l *AccessLog) Source {
+	return SourceFunc(func(_ context.Context, limit int) ([]string, error) {
+		return l.Top(limit), nil
+	})
+}
+
+func File(path string) Source {
+	return SourceFunc(func(_ context.Context, limit int) ([]string, error) {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("uid file: %w", err)
+		}
+		defer f.Close()
+
+		var uids []string
+		seen := make(map[string]struct{})
+		sc := bufio.NewScanner(f)
+		for sc.Scan() && len(uids) < limit {
+			uid := strings.TrimSpace(sc.Text())
+			if uid == "" || strings.HasPrefix(uid, "#") {
+				continue
+			}
+			if _, dup := seen[uid]; dup {
+				continue
+			}
+			seen[uid] = struct{}{}
+			uids = append(uids, uid)
+		}
+		if err := sc.Err(); err != nil {
+			return nil, fmt.Errorf("uid file read: %w", err)
+		}
+		return uids, nil
+	})
+}
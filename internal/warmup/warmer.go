@@ -0,0 +1,155 @@
+package warmup
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/mrussa/L0/internal/repo"
+)
+
+const (
+	defaultWorkers      = 4
+	defaultChunk        = 100
+	defaultListTimeout  = 10 * time.Second
+	defaultChunkTimeout = 5 * time.Second
+)
+
+type OrderLoader interface {
+	GetOrders(ctx context.Context, uids []string) ([]repo.Order, error)
+}
+
+type OrderCache interface {
+	Set(uid string, o repo.Order)
+}
+
+type Result struct {
+	Requested      int
+	Warmed         int
+	Failed         int
+	Skipped        int
+	Duration       time.Duration
+	BudgetExceeded bool
+}
+
+type Warmer struct {
+	Source Source
+	Loader OrderLoader
+	Cache  OrderCache
+	Log    *slog.Logger
+
+	Limit        int
+	Workers      int
+	Chunk        int
+	Budget       time.Duration
+	ListTimeout  time.Duration
+	ChunkTimeout time.Duration
+}
+
+func New(src Source, loader OrderLoader, c OrderCache, log *slog.Logger) *Warmer {
+	return &Warmer{
+		Source:       src,
+		Loader:       loader,
+		Cache:        c,
+		Log:          log,
+		Workers:      defaultWorkers,
+		Chunk:        defaultChunk,
+		ListTimeout:  defaultListTimeout,
+		ChunkTimeout: defaultChunkTimeout,
+	}
+}
+
+func (w *Warmer) Run(ctx context.Context) (res Result, err error) {
+	if w.Limit <= 0 {
+		return res, nil
+	}
+	start := time.Now()
+	defer func() { res.Duration = time.Since(start) }()
+
+	runCtx := ctx
+	if w.Budget > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, w.Budget)
+		defer cancel()
+	}
+
+	ctxList, cancel := context.WithTimeout(runCtx, w.ListTimeout)
+	uids, err := w.Source.UIDs(ctxList, w.Limit)
+	cancel()
+	if err != nil {
+		w.Log.ErrorContext(ctx, "warm list failed", "err", err)
+		return res, err
+	}
+	res.Requested = len(uids)
+
+	chunk := max(w.Chunk, 1)
+	jobs := make(chan []string)
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for range max(w.Workers, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for part := range jobs {
+				warmed, failed, skipped := w.load(runCtx, part)
+				mu.Lock()
+				res.Warmed += warmed
+				res.Failed += failed
+				res.Skipped += skipped
+				mu.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for i := 0; i < len(uids); i += chunk {
+		part := uids[i:min(i+chunk, len(uids))]
+		select {
+		case jobs <- part:
+		case <-runCtx.Done():
+			mu.Lock()
+			res.Skipped += len(uids) - i
+			mu.Unlock()
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return res, err
+	}
+	if errors.Is(runCtx.Err(), context.DeadlineExceeded) {
+		res.BudgetExceeded = true
+		w.Log.WarnContext(ctx, "warm budget exceeded", "budget", w.Budget, "skipped", res.Skipped)
+	}
+	w.Log.InfoContext(ctx, "cache warmed",
+		"warmed", res.Warmed, "failed", res.Failed, "skipped", res.Skipped,
+		"took", time.Since(start).Round(time.Millisecond))
+	return res, nil
+}
+
+func (w *Warmer) load(ctx context.Context, uids []string) (warmed, failed, skipped int) {
+	if ctx.Err() != nil {
+		return 0, 0, len(uids)
+	}
+	ctxChunk, cancel := context.WithTimeout(ctx, w.ChunkTimeout)
+	defer cancel()
+
+	orders, err := w.Loader.GetOrders(ctxChunk, uids)
+	if err != nil {
+		if ctx.Err() != nil {
+			return 0, 0, len(uids)
+		}
+		w.Log.WarnContext(ctx, "warm chunk failed", "size", len(uids), "err", err)
+		return 0, len(uids), 0
+	}
+	for _, o := range orders {
+		w.Cache.Set(o.OrderUID, o)
+	}
+	return len(orders), len(uids) - len(orders), 0
+}
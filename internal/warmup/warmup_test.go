@@ -0,0 +1,209 @@
+package warmup_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrussa/L0/internal/repo"
+	"github.com/mrussa/L0/internal/warmup"
+)
+
+type fakeLoader struct {
+	missing map[string]bool
+	fail    map[string]bool
+	block   bool
+
+	calls    atomic.Int32
+	inFlight atomic.Int32
+	peak     atomic.Int32
+}
+
+func (l *fakeLoader) GetOrders(ctx context.Context, uids []string) ([]repo.Order, error) {
+	l.calls.Add(1)
+	n := l.inFlight.Add(1)
+	defer l.inFlight.Add(-1)
+	for {
+		p := l.peak.Load()
+		if n <= p || l.peak.CompareAndSwap(p, n) {
+			break
+		}
+	}
+
+	if l.block {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	out := make([]repo.Order, 0, len(uids))
+	for _, uid := range uids {
+		if l.fail[uid] {
+			return nil, errors.New("db down")
+		}
+		if !l.missing[uid] {
+			out = append(out, repo.Order{OrderUID: uid})
+		}
+	}
+	return out, nil
+}
+
+type mapCache struct {
+	mu sync.Mutex
+	m  map[string]repo.Order
+}
+
+func (c *mapCache) Set(uid string, o repo.Order) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.m == nil {
+		c.m = make(map[string]repo.Order)
+	}
+	c.m[uid] = o
+}
+
+func (c *mapCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.m)
+}
+
+func staticSource(uids ...string) warmup.Source {
+	return warmup.SourceFunc(func(_ context.Context, limit int) ([]string, error) {
+		return uids[:min(limit, len(uids))], nil
+	})
+}
+
+var discard = slog.New(slog.DiscardHandler)
+
+func TestWarmer_ConcurrentChunks(t *testing.T) {
+	t.Parallel()
+	loader := &fakeLoader{missing: map[string]bool{"e": true}, fail: map[string]bool{"g": true}}
+	c := &mapCache{}
+
+	w := warmup.New(staticSource("a", "b", "c", "d", "e", "f", "g", "h"), loader, c, discard)
+	w.Limit = 7
+	w.Workers = 3
+	w.Chunk = 2
+
+	res, err := w.Run(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 7, res.Requested)
+	require.Equal(t, 5, res.Warmed, "e нет в БД, чанк с g упал")
+	require.Equal(t, 2, res.Failed)
+	require.Equal(t, 0, res.Skipped)
+	require.False(t, res.BudgetExceeded)
+	require.Equal(t, 5, c.len())
+	require.EqualValues(t, 4, loader.calls.Load())
+	require.LessOrEqual(t, loader.peak.Load(), int32(3))
+	require.Greater(t, loader.peak.Load(), int32(1), "чанки должны грузиться параллельно")
+}
+
+func TestWarmer_BudgetExceeded(t *testing.T) {
+	t.Parallel()
+	loader := &fakeLoader{block: true}
+	w := warmup.New(staticSource("a", "b", "c", "d", "e"), loader, &mapCache{}, discard)
+	w.Limit = 5
+	w.Workers = 1
+	w.Chunk = 1
+	w.Budget = 30 * time.Millisecond
+
+	res, err := w.Run(context.Background())
+	require.NoError(t, err)
+	require.True(t, res.BudgetExceeded)
+	require.Equal(t, 0, res.Warmed)
+	require.Equal(t, 0, res.Failed)
+	require.Equal(t, 5, res.Skipped)
+	require.Less(t, res.Duration, time.Second)
+}
+
+func TestWarmer_ParentCanceled(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	w := warmup.New(staticSource("a"), &fakeLoader{}, &mapCache{}, discard)
+	w.Limit = 1
+
+	_, err := w.Run(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestWarmer_NoLimitAndSourceError(t *testing.T) {
+	t.Parallel()
+	loader := &fakeLoader{}
+	w := warmup.New(staticSource("a"), loader, &mapCache{}, discard)
+	res, err := w.Run(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, warmup.Result{}, res)
+	require.Zero(t, loader.calls.Load())
+
+	boom := errors.New("boom")
+	w = warmup.New(warmup.SourceFunc(func(context.Context, int) ([]string, error) { return nil, boom }), loader, &mapCache{}, discard)
+	w.Limit = 10
+	_, err = w.Run(context.Background())
+	require.ErrorIs(t, err, boom)
+}
+
+func TestFileSource(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "uids.txt")
+	require.NoError(t, os.WriteFile(path, []byte("# hot orders\nb\n\n  a  \nb\nc\nd\n"), 0o600))
+
+	uids, err := warmup.File(path).UIDs(context.Background(), 3)
+	require.NoError(t, err)
+	require.Equal(t, []string{"b", "a", "c"}, uids)
+
+	_, err = warmup.File(filepath.Join(t.TempDir(), "nope")).UIDs(context.Background(), 3)
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestAccessLog_TopAndDecay(t *testing.T) {
+	t.Parallel()
+	l := warmup.NewAccessLog(3)
+	for _, uid := range []string{"a", "b", "b", "c", "c", "c", "b"} {
+		l.Record(uid)
+	}
+	require.Equal(t, []string{"b", "c", "a"}, l.Top(10))
+	require.Equal(t, []string{"b"}, l.Top(1))
+
+	l.Record("d")
+	require.Equal(t, 3, l.Len(), "при переполнении счётчики делятся пополам, единицы выпадают")
+	require.Equal(t, []string{"b", "c", "d"}, l.Top(10))
+
+	var nilLog *warmup.AccessLog
+	require.NotPanics(t, func() { nilLog.Record("x") })
+}
+
+func TestAccessLog_SaveLoad(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "access.json")
+
+	src := warmup.NewAccessLog(0)
+	src.Record("a")
+	src.Record("b")
+	src.Record("b")
+	require.NoError(t, src.Save(path))
+
+	dst := warmup.NewAccessLog(0)
+	dst.Record("a")
+	dst.Record("a")
+	dst.Record("a")
+	require.NoError(t, dst.Load(path))
+	require.Equal(t, []string{"a", "b"}, dst.Top(2))
+
+	uids, err := warmup.Frequent(dst).UIDs(context.Background(), 1)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a"}, uids)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"version":2,"counts":{}}`), 0o600))
+	require.ErrorIs(t, dst.Load(path), warmup.ErrAccessLogVersion)
+	require.ErrorIs(t, dst.Load(filepath.Join(t.TempDir(), "nope")), os.ErrNotExist)
+}